@@ -0,0 +1,250 @@
+package wecombot
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultArchiveName SendFiles 默认的压缩包文件名
+const DefaultArchiveName = "attachments.zip"
+
+// minVolumeSize 分卷大小的下限
+const minVolumeSize = 1 << 10
+
+// ArchiveOptions 打包发送选项
+type ArchiveOptions struct {
+	// Name 压缩包的文件名，缺少 .zip 扩展名时自动补全。
+	// 为空时 SendFiles 使用 DefaultArchiveName ， SendDirectory 使用目录名。
+	Name string
+	// VolumeSize 每个分卷的最大字节数，为0或超过 MaxFileBytes 时使用 MaxFileBytes ，小于1KB时按1KB处理。
+	VolumeSize int64
+}
+
+func (opts *ArchiveOptions) volumeSize() int64 {
+	switch {
+	case opts.VolumeSize <= 0 || opts.VolumeSize > MaxFileBytes:
+		return MaxFileBytes
+	case opts.VolumeSize < minVolumeSize:
+		return minVolumeSize
+	}
+	return opts.VolumeSize
+}
+
+// ArchivePart 已发送的压缩包分卷
+type ArchivePart struct {
+	// Filename 分卷文件名。仅有一个分卷时为压缩包文件名，否则为形如 attachments.zip.001 的文件名。
+	Filename string
+	// Size 分卷字节数
+	Size int64
+	// Sha256 分卷内容的 sha256 摘要（十六进制）
+	Sha256 string
+	// MediaID 分卷上传后的 media_id
+	MediaID string
+}
+
+// SendFiles 将文件及目录打包为 zip 后发送
+func (bot *Bot) SendFiles(paths []string, opts ArchiveOptions) ([]*ArchivePart, error) {
+	return bot.SendFilesContext(context.Background(), paths, opts)
+}
+
+// SendFilesContext 将文件及目录打包为 zip 后发送，可通过 ctx 取消或设置超时。
+// 压缩包超过分卷大小时按顺序拆分为多个分卷（接收方可通过 cat attachments.zip.* > attachments.zip 合并），
+// 每个分卷作为文件消息发送，最后发送列出各分卷大小及 sha256 摘要的 Markdown 消息。返回已发送的分卷。
+// paths 中的符号链接会被跟随，目录中的符号链接等非普通文件会被忽略； paths 中没有任何普通文件时返回 ErrEmptyArchive ，
+// 不同路径中的文件在压缩包内重名时返回 ErrDuplicateArchiveEntry ，均不会发出请求。
+func (bot *Bot) SendFilesContext(ctx context.Context, paths []string, opts ArchiveOptions) ([]*ArchivePart, error) {
+	name := opts.Name
+	if name == "" {
+		name = DefaultArchiveName
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".zip") {
+		name += ".zip"
+	}
+
+	f, err := os.CreateTemp("", "wecombot-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err = writeZip(f, paths); err != nil {
+		return nil, err
+	}
+	total, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := volumeSizes(total, opts.volumeSize())
+	parts := make([]*ArchivePart, 0, len(sizes))
+	var off int64
+	for i, size := range sizes {
+		part := ArchivePart{Filename: name, Size: size}
+		if len(sizes) > 1 {
+			part.Filename = fmt.Sprintf("%s.%03d", name, i+1)
+		}
+
+		h := sha256.New()
+		if _, err = io.Copy(h, io.NewSectionReader(f, off, size)); err != nil {
+			return parts, err
+		}
+		part.Sha256 = hex.EncodeToString(h.Sum(nil))
+
+		ret, err := bot.UploadMediaReaderContext(ctx, NormalFile, io.NewSectionReader(f, off, size), size, part.Filename)
+		if err != nil {
+			return parts, err
+		}
+		part.MediaID = ret.MediaID
+
+		var msg FileMessage
+		msg.File.MediaID = ret.MediaID
+		if err = bot.SendFileMessageContext(ctx, &msg); err != nil {
+			return parts, err
+		}
+		parts = append(parts, &part)
+		off += size
+	}
+
+	return parts, bot.SendMarkdownSplitContext(ctx, archiveManifest(name, total, parts), SplitOptions{})
+}
+
+// SendDirectory 将目录打包为 zip 后发送
+func (bot *Bot) SendDirectory(dir string, opts ArchiveOptions) ([]*ArchivePart, error) {
+	return bot.SendDirectoryContext(context.Background(), dir, opts)
+}
+
+// SendDirectoryContext 将目录打包为 zip 后发送，可通过 ctx 取消或设置超时。详见 SendFilesContext 。
+func (bot *Bot) SendDirectoryContext(ctx context.Context, dir string, opts ArchiveOptions) ([]*ArchivePart, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	if opts.Name == "" {
+		opts.Name = filepath.Base(filepath.Clean(dir))
+	}
+	return bot.SendFilesContext(ctx, []string{dir}, opts)
+}
+
+// ErrEmptyArchive 待打包的路径中没有任何普通文件
+var ErrEmptyArchive = errors.New("no regular file to archive")
+
+// ErrDuplicateArchiveEntry 不同路径中的文件在压缩包内的文件名相同
+var ErrDuplicateArchiveEntry = errors.New("duplicate archive entry")
+
+// writeZip 将文件及目录写入 zip ，目录中的文件保留以该目录名开头的相对路径。
+// 作为参数的路径本身为符号链接时会跟随至其指向的文件或目录，压缩包内仍使用该路径的名称。
+func writeZip(w io.Writer, paths []string) error {
+	zw := zip.NewWriter(w)
+	seen := make(map[string]string)
+	for _, root := range paths {
+		root = filepath.Clean(root)
+		// filepath.WalkDir 不会跟随作为根的符号链接，需预先解析。
+		target, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return err
+		}
+		base := filepath.Base(root)
+		err = filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			rel, err := filepath.Rel(target, path)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(filepath.Join(base, rel))
+			if prev, ok := seen[name]; ok {
+				return fmt.Errorf("%w: %s (%s and %s)", ErrDuplicateArchiveEntry, name, prev, path)
+			}
+			seen[name] = path
+			return addZipFile(zw, path, name, d)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(seen) == 0 {
+		return ErrEmptyArchive
+	}
+	return zw.Close()
+}
+
+func addZipFile(zw *zip.Writer, path, name string, d fs.DirEntry) error {
+	fi, err := d.Info()
+	if err != nil {
+		return err
+	}
+	hdr, err := zip.FileInfoHeader(fi)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// volumeSizes 将 total 字节均匀拆分为不超过 max 字节的若干分卷，避免最后一个分卷过小而低于上传下限。
+func volumeSizes(total, max int64) []int64 {
+	n := (total + max - 1) / max
+	if n <= 1 {
+		return []int64{total}
+	}
+	size := (total + n - 1) / n
+	sizes := make([]int64, 0, n)
+	for ; total > 0; total -= size {
+		if total < size {
+			size = total
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// archiveManifest 返回分卷清单的 Markdown 内容
+func archiveManifest(name string, total int64, parts []*ArchivePart) string {
+	b := NewMarkdownBuilder()
+	b.Bold(name).Text(fmt.Sprintf(" 共%d个分卷，合计%s", len(parts), formatSize(total))).Newline()
+	for _, one := range parts {
+		b.Raw("> ").Text(one.Filename + " " + formatSize(one.Size) + " ").Code(one.Sha256).Newline()
+	}
+	if len(parts) > 1 {
+		b.Comment("合并：").Code(fmt.Sprintf("cat %s.* > %s", name, name)).Newline()
+	}
+	return b.String()
+}
+
+// formatSize 返回易读的文件大小
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,195 @@
+package wecombot_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestVolumeSizes(t *testing.T) {
+	tests := []struct {
+		total, max int64
+		want       []int64
+	}{
+		{total: 10, max: 100, want: []int64{10}},
+		{total: 100, max: 100, want: []int64{100}},
+		{total: 101, max: 100, want: []int64{51, 50}},
+		{total: 201, max: 100, want: []int64{67, 67, 67}},
+		{total: 250, max: 100, want: []int64{84, 84, 82}},
+	}
+	for _, tt := range tests {
+		if got := wecombot.VolumeSizes(tt.total, tt.max); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("volumeSizes(%d, %d) = %v, want %v", tt.total, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "logs")
+	for name, content := range map[string]string{
+		"logs/app.log":        "app",
+		"logs/nested/gc.log":  "gc",
+		"crash.dump":          "dump",
+		"logs/nested/.hidden": "hidden",
+	} {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := wecombot.WriteZip(&buf, []string{dir + "/", filepath.Join(tmp, "crash.dump")}); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+	}
+	want := map[string]string{
+		"logs/app.log":        "app",
+		"logs/nested/gc.log":  "gc",
+		"logs/nested/.hidden": "hidden",
+		"crash.dump":          "dump",
+	}
+	if !reflect.DeepEqual(got, want) {
+		names := make([]string, 0, len(got))
+		for name := range got {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.Errorf("zip entries = %v, want %v", names, want)
+	}
+}
+
+func TestWriteZip_SymlinkRoot(t *testing.T) {
+	tmp := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmp, "real"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "real", "app.log"), []byte("app"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tmp, "current")
+	if err := os.Symlink(filepath.Join(tmp, "real"), link); err != nil {
+		t.Skip(err)
+	}
+
+	var buf bytes.Buffer
+	if err := wecombot.WriteZip(&buf, []string{link}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "current/app.log" {
+		t.Errorf("zip entries = %v, want [current/app.log]", zr.File)
+	}
+}
+
+func TestWriteZip_Invalid(t *testing.T) {
+	tmp := t.TempDir()
+	for _, name := range []string{"empty/.keep/", "a/report.txt", "b/report.txt"} {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(name, "/") {
+			if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  error
+	}{
+		{name: "无路径", paths: nil, want: wecombot.ErrEmptyArchive},
+		{name: "空目录", paths: []string{filepath.Join(tmp, "empty")}, want: wecombot.ErrEmptyArchive},
+		{name: "重名文件", paths: []string{filepath.Join(tmp, "a", "report.txt"), filepath.Join(tmp, "b", "report.txt")}, want: wecombot.ErrDuplicateArchiveEntry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := wecombot.WriteZip(io.Discard, tt.paths); !errors.Is(err, tt.want) {
+				t.Errorf("writeZip() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBot_SendDirectory(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// 随机内容几乎无法压缩，确保压缩包被拆分为多个分卷
+	content := make([]byte, 10<<10)
+	rand.New(rand.NewSource(1)).Read(content)
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parts, err := srv.Bot("test-key").SendDirectory(dir, wecombot.ArchiveOptions{VolumeSize: 4 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 3 || parts[0].Filename != "logs.zip.001" {
+		t.Fatalf("parts = %d (%s), want 3 volumes named logs.zip.001...", len(parts), parts[0].Filename)
+	}
+
+	// 按顺序合并分卷后应为完整的压缩包
+	var archive []byte
+	for i, media := range srv.Media() {
+		sum := sha256.Sum256(media.Data)
+		if media.Filename != parts[i].Filename || hex.EncodeToString(sum[:]) != parts[i].Sha256 {
+			t.Errorf("volume %d = %s (%x), want %s (%s)", i, media.Filename, sum, parts[i].Filename, parts[i].Sha256)
+		}
+		archive = append(archive, media.Data...)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "logs/app.log" {
+		t.Errorf("unexpected zip entries")
+	}
+
+	if n := len(srv.Files()); n != 3 {
+		t.Errorf("received %d file messages, want 3", n)
+	}
+	srv.ExpectMarkdown(t, parts[2].Sha256)
+}
@@ -0,0 +1,270 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+// ErrQueueClosed 异步发送队列已关闭
+var ErrQueueClosed = errors.New("async queue closed")
+
+const (
+	// DefaultAsyncWorkers 默认的异步发送 worker 数量
+	DefaultAsyncWorkers = 4
+	// DefaultAsyncQueueSize 默认的每个 worker 的队列长度
+	DefaultAsyncQueueSize = 100
+)
+
+// AsyncQueue 异步发送队列，可被多个 Bot 共享。
+// 同一 webhook key 的消息总是由同一 worker 按入队顺序依次投递，不同 key 的消息则由不同 worker 并行投递。
+type AsyncQueue struct {
+	shards  []chan *asyncJob
+	onError func(msg Message, err error)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	closing   chan struct{}
+	closeOnce sync.Once
+	// mu 保证 Close 开始等待 senders 后不会再有新的入队方登记
+	mu      sync.RWMutex
+	senders sync.WaitGroup
+
+	pmu     sync.Mutex
+	pending int
+	idle    []chan struct{}
+}
+
+type asyncJob struct {
+	bot      *Bot
+	msg      Message
+	outboxID string
+	result   chan error
+}
+
+// NewAsyncQueue 返回异步发送队列。workers 为 worker 数量，size 为每个 worker 的队列长度。
+func NewAsyncQueue(workers, size int, opts ...func(*AsyncQueue)) *AsyncQueue {
+	if workers <= 0 {
+		workers = DefaultAsyncWorkers
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	q := AsyncQueue{
+		shards:  make([]chan *asyncJob, workers),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	for _, setter := range opts {
+		setter(&q)
+	}
+
+	var wg sync.WaitGroup
+	for i := range q.shards {
+		q.shards[i] = make(chan *asyncJob, size)
+		wg.Add(1)
+		go func(jobs <-chan *asyncJob) {
+			defer wg.Done()
+			q.work(jobs)
+		}(q.shards[i])
+	}
+	go func() {
+		wg.Wait()
+		close(q.done)
+	}()
+	return &q
+}
+
+// WithErrorHandler 设置异步发送失败时的回调函数
+func WithErrorHandler(fn func(msg Message, err error)) func(*AsyncQueue) {
+	return func(q *AsyncQueue) {
+		q.onError = fn
+	}
+}
+
+func (q *AsyncQueue) work(jobs <-chan *asyncJob) {
+	for job := range jobs {
+		err := job.bot.send(q.ctx, job.msg)
+		if err == nil && job.outboxID != "" {
+			err = job.bot.outbox.Done(job.outboxID)
+		}
+		if err != nil && q.onError != nil {
+			q.onError(job.msg, err)
+		}
+		job.result <- err
+		close(job.result)
+		q.add(-1)
+	}
+}
+
+// enqueue 将消息放入 bot 对应的 worker 队列，队列已满时阻塞直至有空位、队列关闭或 ctx 结束。
+func (q *AsyncQueue) enqueue(ctx context.Context, job *asyncJob) error {
+	q.mu.RLock()
+	select {
+	case <-q.closing:
+		q.mu.RUnlock()
+		return ErrQueueClosed
+	default:
+	}
+	q.senders.Add(1)
+	q.mu.RUnlock()
+	defer q.senders.Done()
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(job.bot.key))
+	shard := q.shards[h.Sum32()%uint32(len(q.shards))]
+
+	q.add(1)
+	select {
+	case shard <- job:
+		return nil
+	case <-q.closing:
+		q.add(-1)
+		return ErrQueueClosed
+	case <-ctx.Done():
+		q.add(-1)
+		return ctx.Err()
+	}
+}
+
+func (q *AsyncQueue) add(delta int) {
+	q.pmu.Lock()
+	defer q.pmu.Unlock()
+
+	q.pending += delta
+	if q.pending == 0 {
+		for _, ch := range q.idle {
+			close(ch)
+		}
+		q.idle = nil
+	}
+}
+
+// Depth 返回已入队但尚未投递完成的消息数量
+func (q *AsyncQueue) Depth() int {
+	q.pmu.Lock()
+	defer q.pmu.Unlock()
+	return q.pending
+}
+
+// Flush 阻塞直至队列中的消息全部投递完成或 ctx 结束
+func (q *AsyncQueue) Flush(ctx context.Context) error {
+	q.pmu.Lock()
+	if q.pending == 0 {
+		q.pmu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	q.idle = append(q.idle, ch)
+	q.pmu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新消息，并阻塞直至队列中的消息全部投递完成。因队列已满而阻塞的入队操作将返回 ErrQueueClosed 。
+// 若 ctx 先行结束，则取消正在进行的投递并返回 ctx.Err() ，尚未投递的消息将以错误结果返回。
+func (q *AsyncQueue) Close(ctx context.Context) error {
+	q.closeOnce.Do(func() {
+		close(q.closing)
+		go func() {
+			// 待所有入队方退出后再关闭 worker 队列，避免向已关闭的 channel 发送。
+			q.mu.Lock()
+			q.mu.Unlock()
+			q.senders.Wait()
+			for _, shard := range q.shards {
+				close(shard)
+			}
+		}()
+	})
+
+	select {
+	case <-q.done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ctx.Err()
+	}
+}
+
+// WithAsync 设置异步发送所使用的 worker 数量及每个 worker 的队列长度
+func WithAsync(workers, size int, opts ...func(*AsyncQueue)) func(*Bot) {
+	return func(bot *Bot) {
+		bot.queue = NewAsyncQueue(workers, size, opts...)
+	}
+}
+
+// WithAsyncQueue 设置异步发送队列，可借此在多个 Bot 间共享同一组 worker 。
+func WithAsyncQueue(q *AsyncQueue) func(*Bot) {
+	return func(bot *Bot) {
+		bot.queue = q
+	}
+}
+
+// asyncQueue 返回 bot 的异步发送队列。未设置时若 create 为 true 则创建默认队列，否则返回 nil 。
+func (bot *Bot) asyncQueue(create bool) *AsyncQueue {
+	bot.queueMu.Lock()
+	defer bot.queueMu.Unlock()
+	if bot.queue == nil && create {
+		bot.queue = NewAsyncQueue(DefaultAsyncWorkers, DefaultAsyncQueueSize)
+	}
+	return bot.queue
+}
+
+// SendAsync 将消息放入异步发送队列并立即返回。返回的 channel 会在消息投递完成后收到发送结果（成功时为 nil ）。
+// ctx 仅作用于入队过程（队列已满时阻塞等待），不影响消息的投递。若设置了发件箱，消息会在入队前写入发件箱。
+func (bot *Bot) SendAsync(ctx context.Context, msg Message) <-chan error {
+	job := asyncJob{
+		bot:    bot,
+		msg:    msg,
+		result: make(chan error, 1),
+	}
+	if bot.outbox != nil {
+		job.outboxID = newIdempotencyKey()
+		if _, err := bot.outbox.Put(bot.key, job.outboxID, msg); err != nil {
+			job.result <- err
+			close(job.result)
+			return job.result
+		}
+	}
+	if err := bot.asyncQueue(true).enqueue(ctx, &job); err != nil {
+		job.result <- err
+		close(job.result)
+	}
+	return job.result
+}
+
+// QueueDepth 返回异步发送队列中尚未投递完成的消息数量，未使用过异步发送时返回0。
+func (bot *Bot) QueueDepth() int {
+	if q := bot.asyncQueue(false); q != nil {
+		return q.Depth()
+	}
+	return 0
+}
+
+// Flush 阻塞直至异步发送队列中的消息全部投递完成或 ctx 结束，未使用过异步发送时立即返回 nil 。
+func (bot *Bot) Flush(ctx context.Context) error {
+	if q := bot.asyncQueue(false); q != nil {
+		return q.Flush(ctx)
+	}
+	return nil
+}
+
+// Close 关闭异步发送队列，并等待队列中的消息投递完成。若队列为多个 Bot 共享，关闭后其他 Bot 也无法再异步发送。
+// 未使用过异步发送时立即返回 nil 。
+func (bot *Bot) Close(ctx context.Context) error {
+	if q := bot.asyncQueue(false); q != nil {
+		return q.Close(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package wecombot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestBot_SendAsync(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	bot := srv.Bot("test-key", wecombot.WithAsync(2, 10))
+	var results []<-chan error
+	for _, content := range []string{"1", "2", "3"} {
+		var msg wecombot.TextMessage
+		msg.Text.Content = content
+		results = append(results, bot.SendAsync(context.Background(), &msg))
+	}
+	if err := bot.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range results {
+		if err := <-ch; err != nil {
+			t.Errorf("SendAsync() error = %v", err)
+		}
+	}
+
+	texts := srv.Texts()
+	if len(texts) != 3 || texts[0].Text.Content != "1" || texts[2].Text.Content != "3" {
+		t.Errorf("messages are not delivered in order")
+	}
+	if err := <-bot.SendAsync(context.Background(), &wecombot.TextMessage{}); !errors.Is(err, wecombot.ErrQueueClosed) {
+		t.Errorf("SendAsync() after Close error = %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestAsyncQueue_CloseFullQueue(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+	srv.SetLatency(time.Second)
+
+	bot := srv.Bot("test-key", wecombot.WithAsync(1, 1))
+	results := make(chan (<-chan error), 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			var msg wecombot.TextMessage
+			msg.Text.Content = "hello"
+			results <- bot.SendAsync(context.Background(), &msg)
+		}()
+	}
+	// 1条投递中、1条位于队列中、1条因队列已满而阻塞
+	for deadline := time.Now().Add(time.Second); bot.QueueDepth() < 3; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("QueueDepth() = %d, want 3", bot.QueueDepth())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := bot.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Close() returned after %s, want about 50ms", elapsed)
+	}
+
+	var closed int
+	for i := 0; i < 3; i++ {
+		select {
+		case ch := <-results:
+			if err := <-ch; errors.Is(err, wecombot.ErrQueueClosed) {
+				closed++
+			} else if err == nil {
+				t.Error("SendAsync() error = nil, want delivery to be canceled")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("SendAsync() did not return after Close()")
+		}
+	}
+	if closed != 1 {
+		t.Errorf("%d messages failed with ErrQueueClosed, want 1", closed)
+	}
+}
+
+func TestBot_CloseWithoutAsync(t *testing.T) {
+	bot := wecombot.NewBot("test-key")
+	if n := bot.QueueDepth(); n != 0 {
+		t.Errorf("QueueDepth() = %d, want 0", n)
+	}
+	if err := bot.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+	if err := bot.Close(context.Background()); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
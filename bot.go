@@ -2,35 +2,68 @@ package wecombot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+)
+
+// DefaultBaseURL 企业微信 API 的默认地址
+const DefaultBaseURL = "https://qyapi.weixin.qq.com"
+
+const (
+	sendPath        = "/cgi-bin/webhook/send"
+	uploadMediaPath = "/cgi-bin/webhook/upload_media"
 )
 
 // Bot 企业微信群机器人
 type Bot struct {
+	baseURL    string
 	webhookURL string
 	key        string
 
 	threadSafe bool
+	validate   bool
 	reqbuf     *bytes.Buffer
 	client     *http.Client
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	limitMode  RateLimitMode
+	queue      *AsyncQueue
+	queueMu    sync.Mutex
+	outbox     *Outbox
+	mediaCache MediaCache
+
+	middlewares []Middleware
+	handler     SendFunc
+
+	markdownV2Fallback bool
+	imageOpts          *ImageOptions
 }
 
 // NewBot 返回企业微信群机器人实例
 func NewBot(key string, opts ...func(*Bot)) *Bot {
 	bot := Bot{
-		webhookURL: fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=%s", key),
-		key:        key,
-		client:     http.DefaultClient,
+		baseURL: DefaultBaseURL,
+		key:     key,
+		client:  http.DefaultClient,
 	}
 
 	for _, setter := range opts {
 		setter(&bot)
 	}
 
+	bot.webhookURL = fmt.Sprintf("%s%s?key=%s", bot.baseURL, sendPath, url.QueryEscape(key))
+	bot.handler = bot.roundTrip
+	for i := len(bot.middlewares) - 1; i >= 0; i-- {
+		bot.handler = bot.middlewares[i](bot.handler)
+	}
+
 	if !bot.threadSafe {
 		bot.reqbuf = bytes.NewBuffer(nil)
 	}
@@ -38,6 +71,41 @@ func NewBot(key string, opts ...func(*Bot)) *Bot {
 	return &bot
 }
 
+// ErrInvalidWebhookURL 无效的 webhook 地址
+var ErrInvalidWebhookURL = errors.New("invalid webhook url")
+
+// NewBotFromWebhookURL 根据完整的 webhook 地址返回企业微信群机器人实例。
+// 消息发送与文件上传接口均基于该地址的协议、主机及路径前缀生成，适用于私有化部署或代理网关。
+func NewBotFromWebhookURL(rawURL string, opts ...func(*Bot)) (*Bot, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidWebhookURL, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrInvalidWebhookURL)
+	}
+	if !strings.HasSuffix(u.Path, sendPath) {
+		return nil, fmt.Errorf("%w: path must end with %s", ErrInvalidWebhookURL, sendPath)
+	}
+	key := ExtractKey(rawURL)
+	if key == "" {
+		return nil, fmt.Errorf("%w: missing key", ErrInvalidWebhookURL)
+	}
+
+	baseURL := fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, strings.TrimSuffix(u.Path, sendPath))
+	return NewBot(key, append([]func(*Bot){WithBaseURL(baseURL)}, opts...)...), nil
+}
+
+// WithBaseURL 设置企业微信 API 的基础地址（如 https://qyapi.weixin.qq.com ），用于私有化部署、代理网关或测试服务。
+func WithBaseURL(baseURL string) func(*Bot) {
+	return func(bot *Bot) {
+		bot.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
 // WithThreadSafe 设置线程安全模式
 func WithThreadSafe() func(*Bot) {
 	return func(bot *Bot) {
@@ -56,7 +124,25 @@ var jsonReqHeader = map[string]string{
 	"Content-Type": "application/json",
 }
 
-func (bot *Bot) send(msg interface{}) (err error) {
+func (bot *Bot) send(ctx context.Context, msg Message) (err error) {
+	if bot.validate {
+		if err = msg.Validate(); err != nil {
+			return err
+		}
+	}
+	_, err = bot.handler(ctx, &Request{Key: bot.key, Message: msg})
+	return err
+}
+
+// roundTrip 执行一次接口调用，是中间件链的最内层。
+func (bot *Bot) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	if req.Upload != nil {
+		return bot.postMedia(ctx, req.Upload)
+	}
+	return bot.postMessage(ctx, req.Message)
+}
+
+func (bot *Bot) postMessage(ctx context.Context, msg Message) (res *Response, err error) {
 	var reqBody *bytes.Buffer
 	if bot.threadSafe {
 		reqBody = bytes.NewBuffer(nil)
@@ -66,14 +152,22 @@ func (bot *Bot) send(msg interface{}) (err error) {
 	defer reqBody.Reset()
 
 	if err = json.NewEncoder(reqBody).Encode(msg); err != nil {
-		return err
+		return nil, err
 	}
 
-	var resData resData
-	if err = bot.doPost(bot.webhookURL, jsonReqHeader, reqBody, &resData); err != nil {
-		return err
-	}
-	return resData.ToError()
+	err = bot.withRetry(ctx, func() error {
+		if err := bot.acquire(ctx); err != nil {
+			return err
+		}
+
+		res = new(Response)
+		if err := bot.doPost(ctx, bot.webhookURL, jsonReqHeader, bytes.NewReader(reqBody.Bytes()), res); err != nil {
+			res = nil
+			return err
+		}
+		return res.toError()
+	})
+	return res, err
 }
 
 // isSuccess 返回 http 请求是否成功
@@ -81,11 +175,14 @@ func isSuccess(statusCode int) bool {
 	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
 }
 
-func (bot *Bot) doPost(url string, reqHeader map[string]string, reqBody io.Reader, resData interface{}) error {
-	req, err := http.NewRequest(http.MethodPost, url, reqBody)
+func (bot *Bot) doPost(ctx context.Context, url string, reqHeader map[string]string, reqBody io.Reader, resData interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, reqBody)
 	if err != nil {
 		return err
 	}
+	if sr, ok := reqBody.(*sizedReader); ok {
+		req.ContentLength = sr.size
+	}
 	for k, v := range reqHeader {
 		req.Header.Set(k, v)
 	}
@@ -97,7 +194,8 @@ func (bot *Bot) doPost(url string, reqHeader map[string]string, reqBody io.Reade
 	defer res.Body.Close()
 
 	if !isSuccess(res.StatusCode) {
-		return fmt.Errorf("server response abnormality: %d", res.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+		return &HTTPStatusError{StatusCode: res.StatusCode, Body: body}
 	}
 
 	return json.NewDecoder(res.Body).Decode(resData)
@@ -115,35 +213,6 @@ func (rd *resData) ToError() error {
 	return NewResError(rd.ErrCode, rd.ErrMsg)
 }
 
-// ResError 响应错误
-type ResError struct {
-	errCode int
-	errMsg  string
-}
-
-// NewResError 返回响应错误实例
-func NewResError(code int, msg string) error {
-	return &ResError{
-		errCode: code,
-		errMsg:  msg,
-	}
-}
-
-// ErrCode 返回错误码
-func (e *ResError) ErrCode() int {
-	return e.errCode
-}
-
-// ErrMsg 返回错误消息内容
-func (e *ResError) ErrMsg() string {
-	return e.errMsg
-}
-
-// Error 返回文本形式的错误描述
-func (e *ResError) Error() string {
-	return fmt.Sprintf("[%d]%s", e.errCode, e.errMsg)
-}
-
 // MsgType 消息类型
 type MsgType string
 
@@ -152,6 +221,8 @@ const (
 	TextMsgType MsgType = "text"
 	// MarkdownMsgType Markdown 类型
 	MarkdownMsgType MsgType = "markdown"
+	// MarkdownV2MsgType Markdown V2 类型
+	MarkdownV2MsgType MsgType = "markdown_v2"
 	// ImageMsgType 图片类型
 	ImageMsgType MsgType = "image"
 	// NewsMsgType 图文类型
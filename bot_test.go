@@ -40,3 +40,58 @@ func TestExtractKey(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBotFromWebhookURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		rawURL         string
+		wantErr        bool
+		wantWebhookURL string
+		wantUploadURL  string
+	}{
+		{
+			name:           "官方webhook地址",
+			rawURL:         "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=aaa-bbb",
+			wantWebhookURL: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=aaa-bbb",
+			wantUploadURL:  "https://qyapi.weixin.qq.com/cgi-bin/webhook/upload_media?key=aaa-bbb&type=file",
+		},
+		{
+			name:           "带路径前缀的代理地址",
+			rawURL:         "http://127.0.0.1:8080/wecom/cgi-bin/webhook/send?key=aaa-bbb",
+			wantWebhookURL: "http://127.0.0.1:8080/wecom/cgi-bin/webhook/send?key=aaa-bbb",
+			wantUploadURL:  "http://127.0.0.1:8080/wecom/cgi-bin/webhook/upload_media?key=aaa-bbb&type=file",
+		},
+		{
+			name:    "不支持的协议",
+			rawURL:  "ftp://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=aaa-bbb",
+			wantErr: true,
+		},
+		{
+			name:    "非webhook路径",
+			rawURL:  "https://qyapi.weixin.qq.com/cgi-bin/hello?key=aaa-bbb",
+			wantErr: true,
+		},
+		{
+			name:    "缺少key参数",
+			rawURL:  "https://qyapi.weixin.qq.com/cgi-bin/webhook/send",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, err := NewBotFromWebhookURL(tt.rawURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBotFromWebhookURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if bot.webhookURL != tt.wantWebhookURL {
+				t.Errorf("webhookURL = %v, want %v", bot.webhookURL, tt.wantWebhookURL)
+			}
+			if got := bot.getUploadMediaURL(NormalFile); got != tt.wantUploadURL {
+				t.Errorf("getUploadMediaURL() = %v, want %v", got, tt.wantUploadURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package wecombot
+
+// DescColor 模板卡片来源文字的颜色
+type DescColor uint8
+
+const (
+	// DescColorGray 灰色（默认）
+	DescColorGray DescColor = 0
+	// DescColorBlack 黑色
+	DescColorBlack DescColor = 1
+	// DescColorRed 红色
+	DescColorRed DescColor = 2
+	// DescColorGreen 绿色
+	DescColorGreen DescColor = 3
+)
+
+// ActionType 模板卡片的点击事件类型，适用于 CardAction 、 QuoteArea 、 Jump 及 ImageTextArea 的 Type 字段。
+type ActionType uint8
+
+const (
+	// ActionNone 没有点击事件（ CardAction 不支持）
+	ActionNone ActionType = 0
+	// ActionURL 跳转url
+	ActionURL ActionType = 1
+	// ActionMiniProgram 跳转小程序
+	ActionMiniProgram ActionType = 2
+)
+
+// HorizontalContentType 模板卡片二级标题+文本的类型
+type HorizontalContentType uint8
+
+const (
+	// HorizontalContentText 普通文本
+	HorizontalContentText HorizontalContentType = 0
+	// HorizontalContentURL 链接
+	HorizontalContentURL HorizontalContentType = 1
+	// HorizontalContentMedia 文件附件
+	HorizontalContentMedia HorizontalContentType = 2
+	// HorizontalContentUser 成员详情
+	HorizontalContentUser HorizontalContentType = 3
+)
+
+// optional 返回 s 的指针，s 为空时返回 nil 。
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func uint8Ptr(v uint8) *uint8 {
+	return &v
+}
+
+func newSource(iconURL, desc string, color DescColor) *Source {
+	return &Source{
+		IconURL:   optional(iconURL),
+		Desc:      optional(desc),
+		DescColor: uint8Ptr(uint8(color)),
+	}
+}
+
+func newQuoteArea(tpe ActionType, title, text, url, appid, pagepath string) *QuoteArea {
+	return &QuoteArea{
+		Type:      uint8Ptr(uint8(tpe)),
+		URL:       optional(url),
+		AppID:     optional(appid),
+		PagePath:  optional(pagepath),
+		Title:     optional(title),
+		QuoteText: optional(text),
+	}
+}
+
+func newHorizontalContent(tpe HorizontalContentType, key, value string) *HorizontalContent {
+	one := HorizontalContent{KeyName: key, Value: optional(value)}
+	if tpe != HorizontalContentText {
+		one.Type = uint8Ptr(uint8(tpe))
+	}
+	return &one
+}
+
+func newJump(tpe ActionType, title, url, appid, pagepath string) *Jump {
+	return &Jump{
+		Type:     uint8Ptr(uint8(tpe)),
+		Title:    title,
+		URL:      optional(url),
+		AppID:    optional(appid),
+		PagePath: optional(pagepath),
+	}
+}
+
+// TextNoticeCardBuilder 文本通知模板卡片消息构建器
+type TextNoticeCardBuilder struct {
+	msg TextNoticeTemplateCardMessage
+}
+
+// NewTextNoticeCard 返回文本通知模板卡片消息构建器
+func NewTextNoticeCard() *TextNoticeCardBuilder {
+	b := new(TextNoticeCardBuilder)
+	b.msg.MsgType = TemplateCardMsgType
+	b.msg.TemplateCard.CardType = TextNoticeCardType
+	return b
+}
+
+// Source 设置卡片来源样式
+func (b *TextNoticeCardBuilder) Source(iconURL, desc string, color DescColor) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.Source = newSource(iconURL, desc, color)
+	return b
+}
+
+// Title 设置一级标题及标题辅助信息，desc 为空时不设置辅助信息。
+func (b *TextNoticeCardBuilder) Title(title, desc string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.MainTitle = MainTitle{Title: optional(title), Desc: optional(desc)}
+	return b
+}
+
+// SubTitle 设置二级普通文本
+func (b *TextNoticeCardBuilder) SubTitle(text string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.SubTitleText = optional(text)
+	return b
+}
+
+// Emphasis 设置关键数据样式
+func (b *TextNoticeCardBuilder) Emphasis(title, desc string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.EmphasisContent = &EmphasisContent{Title: optional(title), Desc: optional(desc)}
+	return b
+}
+
+// Quote 设置无点击事件的引用文献样式
+func (b *TextNoticeCardBuilder) Quote(title, text string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.QuoteArea = newQuoteArea(ActionNone, title, text, "", "", "")
+	return b
+}
+
+// QuoteURL 设置点击后跳转 url 的引用文献样式
+func (b *TextNoticeCardBuilder) QuoteURL(title, text, url string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.QuoteArea = newQuoteArea(ActionURL, title, text, url, "", "")
+	return b
+}
+
+// QuoteMiniProgram 设置点击后跳转小程序的引用文献样式
+func (b *TextNoticeCardBuilder) QuoteMiniProgram(title, text, appid, pagepath string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.QuoteArea = newQuoteArea(ActionMiniProgram, title, text, "", appid, pagepath)
+	return b
+}
+
+// AddHorizontalText 添加普通文本类型的二级标题+文本
+func (b *TextNoticeCardBuilder) AddHorizontalText(key, value string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, newHorizontalContent(HorizontalContentText, key, value))
+	return b
+}
+
+// AddHorizontalURL 添加链接类型的二级标题+文本
+func (b *TextNoticeCardBuilder) AddHorizontalURL(key, value, url string) *TextNoticeCardBuilder {
+	one := newHorizontalContent(HorizontalContentURL, key, value)
+	one.URL = optional(url)
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, one)
+	return b
+}
+
+// AddHorizontalMedia 添加文件附件类型的二级标题+文本，filename 为文件名称（要包含文件类型）。
+func (b *TextNoticeCardBuilder) AddHorizontalMedia(key, filename, mediaID string) *TextNoticeCardBuilder {
+	one := newHorizontalContent(HorizontalContentMedia, key, filename)
+	one.MediaID = optional(mediaID)
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, one)
+	return b
+}
+
+// AddHorizontalUser 添加成员详情类型的二级标题+文本
+func (b *TextNoticeCardBuilder) AddHorizontalUser(key, value, userid string) *TextNoticeCardBuilder {
+	one := newHorizontalContent(HorizontalContentUser, key, value)
+	one.UserID = optional(userid)
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, one)
+	return b
+}
+
+// AddJumpURL 添加跳转 url 的跳转指引
+func (b *TextNoticeCardBuilder) AddJumpURL(title, url string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.JumpList = append(b.msg.TemplateCard.JumpList, newJump(ActionURL, title, url, "", ""))
+	return b
+}
+
+// AddJumpMiniProgram 添加跳转小程序的跳转指引
+func (b *TextNoticeCardBuilder) AddJumpMiniProgram(title, appid, pagepath string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.JumpList = append(b.msg.TemplateCard.JumpList, newJump(ActionMiniProgram, title, "", appid, pagepath))
+	return b
+}
+
+// ActionURL 设置点击卡片后跳转的 url
+func (b *TextNoticeCardBuilder) ActionURL(url string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.CardAction = CardAction{Type: uint8(ActionURL), URL: optional(url)}
+	return b
+}
+
+// ActionMiniProgram 设置点击卡片后打开的小程序
+func (b *TextNoticeCardBuilder) ActionMiniProgram(appid, pagepath string) *TextNoticeCardBuilder {
+	b.msg.TemplateCard.CardAction = CardAction{Type: uint8(ActionMiniProgram), AppID: optional(appid), PagePath: optional(pagepath)}
+	return b
+}
+
+// Build 返回构建完成的消息，可直接用于 SendTextNoticeTemplateCardMessage 。返回的消息与构建器互不影响。
+func (b *TextNoticeCardBuilder) Build() *TextNoticeTemplateCardMessage {
+	msg := b.msg
+	card := &msg.TemplateCard
+	card.Source = clonePtr(card.Source)
+	card.EmphasisContent = clonePtr(card.EmphasisContent)
+	card.QuoteArea = clonePtr(card.QuoteArea)
+	card.HorizontalContentList = cloneList(card.HorizontalContentList)
+	card.JumpList = cloneList(card.JumpList)
+	return &msg
+}
+
+// NewsNoticeCardBuilder 图文展示模板卡片消息构建器
+type NewsNoticeCardBuilder struct {
+	msg NewsNoticeTemplateCardMessage
+}
+
+// NewNewsNoticeCard 返回图文展示模板卡片消息构建器
+func NewNewsNoticeCard() *NewsNoticeCardBuilder {
+	b := new(NewsNoticeCardBuilder)
+	b.msg.MsgType = TemplateCardMsgType
+	b.msg.TemplateCard.CardType = NewsNoticeCardType
+	return b
+}
+
+// Source 设置卡片来源样式
+func (b *NewsNoticeCardBuilder) Source(iconURL, desc string, color DescColor) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.Source = newSource(iconURL, desc, color)
+	return b
+}
+
+// Title 设置一级标题及标题辅助信息，desc 为空时不设置辅助信息。
+func (b *NewsNoticeCardBuilder) Title(title, desc string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.MainTitle = MainTitle{Title: optional(title), Desc: optional(desc)}
+	return b
+}
+
+// Image 设置图片样式，aspectRatio 为图片的宽高比，为0时使用默认值1.3。
+func (b *NewsNoticeCardBuilder) Image(url string, aspectRatio float32) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.CardImage = CardImage{URL: url}
+	if aspectRatio != 0 {
+		b.msg.TemplateCard.CardImage.AspectRatio = &aspectRatio
+	}
+	return b
+}
+
+// ImageText 设置无点击事件的左图右文样式
+func (b *NewsNoticeCardBuilder) ImageText(imageURL, title, desc string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.ImageTextArea = &ImageTextArea{
+		Type:     uint8Ptr(uint8(ActionNone)),
+		Title:    optional(title),
+		Desc:     optional(desc),
+		ImageURL: imageURL,
+	}
+	return b
+}
+
+// ImageTextURL 设置点击后跳转 url 的左图右文样式
+func (b *NewsNoticeCardBuilder) ImageTextURL(imageURL, title, desc, url string) *NewsNoticeCardBuilder {
+	b.ImageText(imageURL, title, desc)
+	b.msg.TemplateCard.ImageTextArea.Type = uint8Ptr(uint8(ActionURL))
+	b.msg.TemplateCard.ImageTextArea.URL = optional(url)
+	return b
+}
+
+// ImageTextMiniProgram 设置点击后跳转小程序的左图右文样式
+func (b *NewsNoticeCardBuilder) ImageTextMiniProgram(imageURL, title, desc, appid, pagepath string) *NewsNoticeCardBuilder {
+	b.ImageText(imageURL, title, desc)
+	b.msg.TemplateCard.ImageTextArea.Type = uint8Ptr(uint8(ActionMiniProgram))
+	b.msg.TemplateCard.ImageTextArea.AppID = optional(appid)
+	b.msg.TemplateCard.ImageTextArea.PagePath = optional(pagepath)
+	return b
+}
+
+// Quote 设置无点击事件的引用文献样式
+func (b *NewsNoticeCardBuilder) Quote(title, text string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.QuoteArea = newQuoteArea(ActionNone, title, text, "", "", "")
+	return b
+}
+
+// QuoteURL 设置点击后跳转 url 的引用文献样式
+func (b *NewsNoticeCardBuilder) QuoteURL(title, text, url string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.QuoteArea = newQuoteArea(ActionURL, title, text, url, "", "")
+	return b
+}
+
+// QuoteMiniProgram 设置点击后跳转小程序的引用文献样式
+func (b *NewsNoticeCardBuilder) QuoteMiniProgram(title, text, appid, pagepath string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.QuoteArea = newQuoteArea(ActionMiniProgram, title, text, "", appid, pagepath)
+	return b
+}
+
+// AddVertical 添加卡片二级垂直内容
+func (b *NewsNoticeCardBuilder) AddVertical(title, desc string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.VerticalContentList = append(b.msg.TemplateCard.VerticalContentList, &VerticalContent{Title: title, Desc: optional(desc)})
+	return b
+}
+
+// AddHorizontalText 添加普通文本类型的二级标题+文本
+func (b *NewsNoticeCardBuilder) AddHorizontalText(key, value string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, newHorizontalContent(HorizontalContentText, key, value))
+	return b
+}
+
+// AddHorizontalURL 添加链接类型的二级标题+文本
+func (b *NewsNoticeCardBuilder) AddHorizontalURL(key, value, url string) *NewsNoticeCardBuilder {
+	one := newHorizontalContent(HorizontalContentURL, key, value)
+	one.URL = optional(url)
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, one)
+	return b
+}
+
+// AddHorizontalMedia 添加文件附件类型的二级标题+文本，filename 为文件名称（要包含文件类型）。
+func (b *NewsNoticeCardBuilder) AddHorizontalMedia(key, filename, mediaID string) *NewsNoticeCardBuilder {
+	one := newHorizontalContent(HorizontalContentMedia, key, filename)
+	one.MediaID = optional(mediaID)
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, one)
+	return b
+}
+
+// AddHorizontalUser 添加成员详情类型的二级标题+文本
+func (b *NewsNoticeCardBuilder) AddHorizontalUser(key, value, userid string) *NewsNoticeCardBuilder {
+	one := newHorizontalContent(HorizontalContentUser, key, value)
+	one.UserID = optional(userid)
+	b.msg.TemplateCard.HorizontalContentList = append(b.msg.TemplateCard.HorizontalContentList, one)
+	return b
+}
+
+// AddJumpURL 添加跳转 url 的跳转指引
+func (b *NewsNoticeCardBuilder) AddJumpURL(title, url string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.JumpList = append(b.msg.TemplateCard.JumpList, newJump(ActionURL, title, url, "", ""))
+	return b
+}
+
+// AddJumpMiniProgram 添加跳转小程序的跳转指引
+func (b *NewsNoticeCardBuilder) AddJumpMiniProgram(title, appid, pagepath string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.JumpList = append(b.msg.TemplateCard.JumpList, newJump(ActionMiniProgram, title, "", appid, pagepath))
+	return b
+}
+
+// ActionURL 设置点击卡片后跳转的 url
+func (b *NewsNoticeCardBuilder) ActionURL(url string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.CardAction = CardAction{Type: uint8(ActionURL), URL: optional(url)}
+	return b
+}
+
+// ActionMiniProgram 设置点击卡片后打开的小程序
+func (b *NewsNoticeCardBuilder) ActionMiniProgram(appid, pagepath string) *NewsNoticeCardBuilder {
+	b.msg.TemplateCard.CardAction = CardAction{Type: uint8(ActionMiniProgram), AppID: optional(appid), PagePath: optional(pagepath)}
+	return b
+}
+
+// Build 返回构建完成的消息，可直接用于 SendNewsNoticeTemplateCardMessage 。返回的消息与构建器互不影响。
+func (b *NewsNoticeCardBuilder) Build() *NewsNoticeTemplateCardMessage {
+	msg := b.msg
+	card := &msg.TemplateCard
+	card.Source = clonePtr(card.Source)
+	card.ImageTextArea = clonePtr(card.ImageTextArea)
+	card.QuoteArea = clonePtr(card.QuoteArea)
+	card.VerticalContentList = cloneList(card.VerticalContentList)
+	card.HorizontalContentList = cloneList(card.HorizontalContentList)
+	card.JumpList = cloneList(card.JumpList)
+	return &msg
+}
+
+// clonePtr 返回 p 所指向值的副本。构建器中的字符串等可选字段在设置后不会被修改，因此无需复制。
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// cloneList 返回列表及其中每个元素的副本
+func cloneList[T any](items []*T) []*T {
+	if items == nil {
+		return nil
+	}
+	list := make([]*T, len(items))
+	for i, one := range items {
+		list[i] = clonePtr(one)
+	}
+	return list
+}
@@ -0,0 +1,86 @@
+package wecombot
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTextNoticeCardBuilder(t *testing.T) {
+	msg := NewTextNoticeCard().
+		Source("https://img/icon.png", "企业微信", DescColorBlack).
+		Title("欢迎使用企业微信", "邀请您加入").
+		Emphasis("100", "数据含义").
+		SubTitle("下载企业微信").
+		AddHorizontalText("邀请人", "张三").
+		AddHorizontalURL("官网", "点击访问", "https://work.weixin.qq.com").
+		AddJumpURL("了解企业微信", "https://work.weixin.qq.com").
+		ActionURL("https://work.weixin.qq.com").
+		Build()
+
+	want := `{"msgtype":"template_card","template_card":{"card_type":"text_notice",` +
+		`"source":{"icon_url":"https://img/icon.png","desc":"企业微信","desc_color":1},` +
+		`"main_title":{"title":"欢迎使用企业微信","desc":"邀请您加入"},` +
+		`"emphasis_content":{"title":"100","desc":"数据含义"},"quote_area":null,"sub_title_text":"下载企业微信",` +
+		`"horizontal_content_list":[{"type":null,"keyname":"邀请人","value":"张三","url":null,"media_id":null,"userid":null},` +
+		`{"type":1,"keyname":"官网","value":"点击访问","url":"https://work.weixin.qq.com","media_id":null,"userid":null}],` +
+		`"jump_list":[{"type":1,"title":"了解企业微信","url":"https://work.weixin.qq.com","appid":null,"pagepath":null}],` +
+		`"card_action":{"type":1,"url":"https://work.weixin.qq.com","appid":null,"pagepath":null}}}`
+	if got, err := json.Marshal(msg); err != nil || string(got) != want {
+		t.Errorf("json.Marshal() = %s, %v, want %s", got, err, want)
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestNewsNoticeCardBuilder(t *testing.T) {
+	msg := NewNewsNoticeCard().
+		Title("欢迎", "").
+		Image("https://img/card.png", 1.3).
+		ImageTextURL("https://img/left.png", "标题", "描述", "https://work.weixin.qq.com").
+		AddVertical("惊喜红包等你来拿", "").
+		AddJumpMiniProgram("小程序", "APPID", "pages/index").
+		ActionMiniProgram("APPID", "pages/index").
+		Build()
+
+	want := `{"msgtype":"template_card","template_card":{"card_type":"news_notice","source":null,` +
+		`"main_title":{"title":"欢迎","desc":null},"card_image":{"url":"https://img/card.png","aspect_ratio":1.3},` +
+		`"image_text_area":{"type":1,"url":"https://work.weixin.qq.com","appid":null,"pagepath":null,"title":"标题","desc":"描述","image_url":"https://img/left.png"},` +
+		`"quote_area":null,"vertical_content_list":[{"title":"惊喜红包等你来拿","desc":null}],"horizontal_content_list":null,` +
+		`"jump_list":[{"type":2,"title":"小程序","url":null,"appid":"APPID","pagepath":"pages/index"}],` +
+		`"card_action":{"type":2,"url":null,"appid":"APPID","pagepath":"pages/index"}}}`
+	if got, err := json.Marshal(msg); err != nil || string(got) != want {
+		t.Errorf("json.Marshal() = %s, %v, want %s", got, err, want)
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestCardBuilder_BuildIndependent(t *testing.T) {
+	text := NewTextNoticeCard().Title("标题", "").ActionURL("https://work.weixin.qq.com")
+	for i := 0; i < 4; i++ { // 使切片留有多余容量，后续 append 不会重新分配
+		text.AddJumpURL("跳转", "https://work.weixin.qq.com")
+	}
+	text.msg.TemplateCard.JumpList = text.msg.TemplateCard.JumpList[:1]
+	first := text.Build()
+	first.TemplateCard.JumpList[0].Title = "已修改"
+	text.AddJumpURL("新增", "https://work.weixin.qq.com").Source("", "来源", DescColorRed)
+	second := text.Build()
+
+	if n := len(first.TemplateCard.JumpList); n != 1 || first.TemplateCard.Source != nil {
+		t.Errorf("built message changed by later builder calls: %d jumps, source %v", n, first.TemplateCard.Source)
+	}
+	if got := second.TemplateCard.JumpList; len(got) != 2 || got[0].Title != "跳转" || got[1].Title != "新增" {
+		t.Errorf("builder changed by built message")
+	}
+
+	news := NewNewsNoticeCard().AddVertical("a", "").AddHorizontalText("k", "v")
+	built := news.Build()
+	built.TemplateCard.VerticalContentList[0].Title = "changed"
+	built.TemplateCard.HorizontalContentList[0].KeyName = "changed"
+	again := news.Build()
+	if again.TemplateCard.VerticalContentList[0].Title != "a" || again.TemplateCard.HorizontalContentList[0].KeyName != "k" {
+		t.Errorf("builder changed by built message")
+	}
+}
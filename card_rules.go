@@ -0,0 +1,162 @@
+package wecombot
+
+import "fmt"
+
+// 模板卡片图片宽高比的取值范围
+const (
+	// MinCardImageAspectRatio 图片宽高比的最小值
+	MinCardImageAspectRatio = 1.3
+	// MaxCardImageAspectRatio 图片宽高比的最大值
+	MaxCardImageAspectRatio = 2.25
+)
+
+// cardView 两种模板卡片的公共视图，不存在的字段为 nil 。
+type cardView struct {
+	cardType      CardType
+	mainTitle     *MainTitle
+	subTitleText  *string
+	quoteArea     *QuoteArea
+	imageTextArea *ImageTextArea
+	cardImage     *CardImage
+	verticals     []*VerticalContent
+	horizontals   []*HorizontalContent
+	jumps         []*Jump
+	cardAction    *CardAction
+}
+
+// cardRule 模板卡片的跨字段约束规则
+type cardRule struct {
+	// only 规则仅适用的卡片类型，为空时适用于所有卡片。
+	only CardType
+	// check 检查卡片并记录不满足规则的字段
+	check func(c *cardView, v *validator)
+}
+
+// templateCardRules 模板卡片的约束规则，按顺序依次检查。
+var templateCardRules = []cardRule{
+	{only: TextNoticeCardType, check: func(c *cardView, v *validator) {
+		if isEmpty(c.mainTitle.Title) && isEmpty(c.subTitleText) {
+			v.add("template_card.main_title.title", "is required when template_card.sub_title_text is empty")
+		}
+	}},
+	{only: NewsNoticeCardType, check: func(c *cardView, v *validator) {
+		if c.cardImage.URL == "" && c.imageTextArea == nil {
+			v.add("template_card.card_image.url", "is required when template_card.image_text_area is empty")
+		}
+	}},
+	{only: NewsNoticeCardType, check: func(c *cardView, v *validator) {
+		if r := c.cardImage.AspectRatio; r != nil && (*r < MinCardImageAspectRatio || *r > MaxCardImageAspectRatio) {
+			v.add("template_card.card_image.aspect_ratio", "must be between %v and %v, got %v", MinCardImageAspectRatio, MaxCardImageAspectRatio, *r)
+		}
+	}},
+	{only: TextNoticeCardType, check: func(c *cardView, v *validator) {
+		if c.cardAction.Type == uint8(ActionNone) {
+			v.add("template_card.card_action.type", "is required for %s card", TextNoticeCardType)
+		}
+	}},
+	{check: func(c *cardView, v *validator) {
+		a := c.cardAction
+		switch ActionType(a.Type) {
+		case ActionNone:
+		case ActionURL:
+			requireWhen(v, "template_card.card_action.url", a.URL, "template_card.card_action.type", a.Type)
+		case ActionMiniProgram:
+			requireWhen(v, "template_card.card_action.appid", a.AppID, "template_card.card_action.type", a.Type)
+		default:
+			v.add("template_card.card_action.type", "must be %d or %d, got %d", ActionURL, ActionMiniProgram, a.Type)
+		}
+	}},
+	{check: func(c *cardView, v *validator) {
+		if q := c.quoteArea; q != nil {
+			checkAction(v, "template_card.quote_area", q.Type, q.URL, q.AppID)
+		}
+	}},
+	{only: NewsNoticeCardType, check: func(c *cardView, v *validator) {
+		if a := c.imageTextArea; a != nil {
+			checkAction(v, "template_card.image_text_area", a.Type, a.URL, a.AppID)
+			v.required("template_card.image_text_area.image_url", a.ImageURL != "")
+		}
+	}},
+	{only: NewsNoticeCardType, check: func(c *cardView, v *validator) {
+		for i, one := range c.verticals {
+			field := fmt.Sprintf("template_card.vertical_content_list[%d]", i)
+			if one == nil {
+				v.add(field, "must not be null")
+				continue
+			}
+			v.required(field+".title", one.Title != "")
+		}
+	}},
+	{check: func(c *cardView, v *validator) {
+		for i, one := range c.horizontals {
+			field := fmt.Sprintf("template_card.horizontal_content_list[%d]", i)
+			if one == nil {
+				v.add(field, "must not be null")
+				continue
+			}
+			v.required(field+".keyname", one.KeyName != "")
+			if one.Type == nil {
+				continue
+			}
+			switch HorizontalContentType(*one.Type) {
+			case HorizontalContentText:
+			case HorizontalContentURL:
+				requireWhen(v, field+".url", one.URL, field+".type", *one.Type)
+			case HorizontalContentMedia:
+				requireWhen(v, field+".media_id", one.MediaID, field+".type", *one.Type)
+			case HorizontalContentUser:
+				requireWhen(v, field+".userid", one.UserID, field+".type", *one.Type)
+			default:
+				v.add(field+".type", "must be one of %d, %d, %d, %d, got %d", HorizontalContentText, HorizontalContentURL, HorizontalContentMedia, HorizontalContentUser, *one.Type)
+			}
+		}
+	}},
+	{check: func(c *cardView, v *validator) {
+		for i, one := range c.jumps {
+			field := fmt.Sprintf("template_card.jump_list[%d]", i)
+			if one == nil {
+				v.add(field, "must not be null")
+				continue
+			}
+			v.required(field+".title", one.Title != "")
+			checkAction(v, field, one.Type, one.URL, one.AppID)
+		}
+	}},
+}
+
+// requireWhen 记录在类型字段取值为 tpe 时缺失的必填字段
+func requireWhen(v *validator, field string, value *string, typeField string, tpe uint8) {
+	if isEmpty(value) {
+		v.add(field, "is required when %s is %d", typeField, tpe)
+	}
+}
+
+// isEmpty 返回可选的字符串字段是否未设置或为空
+func isEmpty(s *string) bool {
+	return s == nil || *s == ""
+}
+
+// checkAction 检查可选的点击事件：type 为1时 url 必填，为2时 appid 必填。
+func checkAction(v *validator, field string, tpe *uint8, url, appid *string) {
+	if tpe == nil {
+		return
+	}
+	switch ActionType(*tpe) {
+	case ActionNone:
+	case ActionURL:
+		requireWhen(v, field+".url", url, field+".type", *tpe)
+	case ActionMiniProgram:
+		requireWhen(v, field+".appid", appid, field+".type", *tpe)
+	default:
+		v.add(field+".type", "must be one of %d, %d, %d, got %d", ActionNone, ActionURL, ActionMiniProgram, *tpe)
+	}
+}
+
+// checkCardRules 依次检查适用于该卡片类型的约束规则
+func checkCardRules(c *cardView, v *validator) {
+	for _, rule := range templateCardRules {
+		if rule.only == "" || rule.only == c.cardType {
+			rule.check(c, v)
+		}
+	}
+}
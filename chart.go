@@ -0,0 +1,21 @@
+package wecombot
+
+import (
+	"context"
+
+	"github.com/voidint/wecombot/chart"
+)
+
+// SendChart 将图表渲染为 PNG 图片后发送
+func (bot *Bot) SendChart(c *chart.Chart) (err error) {
+	return bot.SendChartContext(context.Background(), c)
+}
+
+// SendChartContext 将图表渲染为 PNG 图片后发送，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendChartContext(ctx context.Context, c *chart.Chart) (err error) {
+	img, err := c.PNG()
+	if err != nil {
+		return err
+	}
+	return bot.SendImageContext(ctx, img)
+}
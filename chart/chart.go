@@ -0,0 +1,431 @@
+// Package chart 提供无第三方依赖的折线图、柱状图及迷你走势图渲染，输出为 PNG 图片，可直接用于企业微信群机器人的图片消息。
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+// Kind 图表类型
+type Kind uint8
+
+const (
+	// Line 折线图
+	Line Kind = iota
+	// Bar 柱状图
+	Bar
+	// Sparkline 迷你走势图，仅绘制第一个数据序列，不含坐标轴、标题及图例。
+	Sparkline
+)
+
+// 默认尺寸（像素）
+const (
+	DefaultWidth           = 800
+	DefaultHeight          = 400
+	DefaultSparklineWidth  = 240
+	DefaultSparklineHeight = 48
+)
+
+// ErrNoData 图表中没有可绘制的数据
+var ErrNoData = errors.New("chart: no data")
+
+// DefaultPalette 数据序列未指定颜色时依次使用的默认颜色
+var DefaultPalette = []color.Color{
+	color.RGBA{R: 0x54, G: 0x70, B: 0xc6, A: 0xff},
+	color.RGBA{R: 0x91, G: 0xcc, B: 0x75, A: 0xff},
+	color.RGBA{R: 0xfa, G: 0xc8, B: 0x58, A: 0xff},
+	color.RGBA{R: 0xee, G: 0x66, B: 0x66, A: 0xff},
+	color.RGBA{R: 0x73, G: 0xc0, B: 0xde, A: 0xff},
+	color.RGBA{R: 0x3b, G: 0xa2, B: 0x72, A: 0xff},
+}
+
+var (
+	textColor = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	axisColor = color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}
+	gridColor = color.RGBA{R: 0xe6, G: 0xe6, B: 0xe6, A: 0xff}
+)
+
+// Series 数据序列
+type Series struct {
+	// Name 序列名称，用于图例。
+	Name string
+	// Values 数据值，NaN 表示缺失（折线图中断开）。
+	Values []float64
+	// Color 序列颜色，为 nil 时使用 DefaultPalette 中的颜色。
+	Color color.Color
+}
+
+// Chart 图表
+type Chart struct {
+	// Kind 图表类型
+	Kind Kind
+	// Title 标题
+	Title string
+	// Width 宽度（像素），为0时使用默认值。
+	Width int
+	// Height 高度（像素），为0时使用默认值。
+	Height int
+	// Labels X 轴标签，与数据值一一对应，标签过密时会自动间隔显示。
+	Labels []string
+	// Series 数据序列
+	Series []Series
+	// Background 背景色，为 nil 时为白色。
+	Background color.Color
+	// FontScale 文字的放大倍数，为0时使用2。
+	FontScale int
+}
+
+// PNG 渲染图表并编码为 PNG
+func (c *Chart) PNG() ([]byte, error) {
+	img, err := c.Render()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render 渲染图表
+func (c *Chart) Render() (*image.RGBA, error) {
+	w, h := c.Width, c.Height
+	if w <= 0 {
+		w = DefaultWidth
+		if c.Kind == Sparkline {
+			w = DefaultSparklineWidth
+		}
+	}
+	if h <= 0 {
+		h = DefaultHeight
+		if c.Kind == Sparkline {
+			h = DefaultSparklineHeight
+		}
+	}
+
+	lo, hi, n := c.bounds()
+	if n == 0 {
+		return nil, ErrNoData
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var bg color.Color = color.White
+	if c.Background != nil {
+		bg = c.Background
+	}
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	switch c.Kind {
+	case Sparkline:
+		c.drawSparkline(img, lo, hi, n)
+	case Line, Bar:
+		c.drawChart(img, lo, hi, n)
+	default:
+		return nil, fmt.Errorf("chart: unsupported kind %d", c.Kind)
+	}
+	return img, nil
+}
+
+// bounds 返回所有数据值的最小值、最大值及最长序列的长度
+func (c *Chart) bounds() (lo, hi float64, n int) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		if len(s.Values) > n {
+			n = len(s.Values)
+		}
+		for _, v := range s.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return 0, 0, 0
+	}
+	return lo, hi, n
+}
+
+func (c *Chart) seriesColor(i int) color.Color {
+	if c.Series[i].Color != nil {
+		return c.Series[i].Color
+	}
+	return DefaultPalette[i%len(DefaultPalette)]
+}
+
+func (c *Chart) drawSparkline(img *image.RGBA, lo, hi float64, n int) {
+	if len(c.Series) == 0 {
+		return
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	const pad = 3
+	b := img.Bounds()
+	plot := image.Rect(pad, pad, b.Dx()-pad, b.Dy()-pad)
+	drawLineSeries(img, plot, c.Series[0].Values, n, lo, hi, c.seriesColor(0), 1)
+}
+
+func (c *Chart) drawChart(img *image.RGBA, lo, hi float64, n int) {
+	scale := c.FontScale
+	if scale <= 0 {
+		scale = 2
+	}
+	if c.Kind == Bar {
+		lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	}
+	ticks, lo, hi := niceTicks(lo, hi, 5)
+
+	b := img.Bounds()
+	lineHeight := textHeight(scale) + 2*scale
+
+	// 计算绘图区域
+	top := 10
+	if c.Title != "" {
+		top += lineHeight
+	}
+	if c.hasLegend() {
+		top += lineHeight
+	}
+	left := 0
+	labels := make([]string, len(ticks))
+	for i, v := range ticks {
+		labels[i] = formatValue(v)
+		if tw := textWidth(labels[i], scale); tw > left {
+			left = tw
+		}
+	}
+	left += 10 + 2*scale
+	bottom := 10 + lineHeight
+	plot := image.Rect(left, top, b.Dx()-10, b.Dy()-bottom)
+	if plot.Dx() <= 0 || plot.Dy() <= 0 {
+		return
+	}
+
+	// 标题及图例
+	y := 6
+	if c.Title != "" {
+		drawText(img, (b.Dx()-textWidth(c.Title, scale))/2, y, c.Title, textColor, scale)
+		y += lineHeight
+	}
+	if c.hasLegend() {
+		x := plot.Min.X
+		for i, s := range c.Series {
+			fillRect(img, x, y, textHeight(scale), textHeight(scale), c.seriesColor(i))
+			x += textHeight(scale) + 2*scale
+			drawText(img, x, y, s.Name, textColor, scale)
+			x += textWidth(s.Name, scale) + 6*scale
+		}
+	}
+
+	// 网格线及 Y 轴标签
+	for i, v := range ticks {
+		py := valueY(plot, v, lo, hi)
+		hline(img, plot.Min.X, plot.Max.X, py, gridColor)
+		drawText(img, plot.Min.X-5-2*scale-textWidth(labels[i], scale), py-textHeight(scale)/2, labels[i], textColor, scale)
+	}
+
+	// X 轴标签，标签过密时间隔显示
+	if len(c.Labels) > 0 {
+		widest := 0
+		for _, one := range c.Labels {
+			if tw := textWidth(one, scale); tw > widest {
+				widest = tw
+			}
+		}
+		step := 1
+		if slot := float64(plot.Dx()) / float64(n); slot > 0 {
+			step = int(math.Ceil(float64(widest+4*scale) / slot))
+			if step < 1 {
+				step = 1
+			}
+		}
+		for i := 0; i < n && i < len(c.Labels); i += step {
+			px := slotX(plot, i, n)
+			drawText(img, px-textWidth(c.Labels[i], scale)/2, plot.Max.Y+6, c.Labels[i], textColor, scale)
+		}
+	}
+
+	// 坐标轴
+	vline(img, plot.Min.X, plot.Min.Y, plot.Max.Y, axisColor)
+	hline(img, plot.Min.X, plot.Max.X, valueY(plot, math.Max(lo, math.Min(0, hi)), lo, hi), axisColor)
+
+	// 数据
+	if c.Kind == Bar {
+		c.drawBars(img, plot, n, lo, hi)
+		return
+	}
+	for i, s := range c.Series {
+		drawLineSeries(img, plot, s.Values, n, lo, hi, c.seriesColor(i), scale)
+	}
+}
+
+func (c *Chart) hasLegend() bool {
+	for _, s := range c.Series {
+		if s.Name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Chart) drawBars(img *image.RGBA, plot image.Rectangle, n int, lo, hi float64) {
+	slot := float64(plot.Dx()) / float64(n)
+	group := slot * 0.8
+	bar := group / float64(len(c.Series))
+	zero := valueY(plot, math.Max(lo, math.Min(0, hi)), lo, hi)
+
+	for i, s := range c.Series {
+		col := c.seriesColor(i)
+		for j, v := range s.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			x0 := plot.Min.X + int(float64(j)*slot+(slot-group)/2+float64(i)*bar)
+			x1 := plot.Min.X + int(float64(j)*slot+(slot-group)/2+float64(i+1)*bar)
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			y := valueY(plot, v, lo, hi)
+			y0, y1 := y, zero
+			if y0 > y1 {
+				y0, y1 = y1, y0
+			}
+			fillRect(img, x0, y0, x1-x0, y1-y0+1, col)
+		}
+	}
+}
+
+// drawLineSeries 在绘图区域内绘制折线，NaN 值处断开。
+func drawLineSeries(img *image.RGBA, plot image.Rectangle, values []float64, n int, lo, hi float64, c color.Color, thickness int) {
+	prevOK := false
+	var px, py int
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			prevOK = false
+			continue
+		}
+		x, y := slotX(plot, i, n), valueY(plot, v, lo, hi)
+		if prevOK {
+			drawLine(img, px, py, x, y, c, thickness)
+		} else {
+			fillRect(img, x-thickness/2, y-thickness/2, thickness, thickness, c)
+		}
+		px, py, prevOK = x, y, true
+	}
+}
+
+// slotX 返回第 i 个数据点的横坐标，即绘图区域等分后第 i 个槽位的中点。
+func slotX(plot image.Rectangle, i, n int) int {
+	return plot.Min.X + int((float64(i)+0.5)*float64(plot.Dx())/float64(n))
+}
+
+// valueY 返回数据值对应的纵坐标
+func valueY(plot image.Rectangle, v, lo, hi float64) int {
+	if hi == lo {
+		return plot.Min.Y + plot.Dy()/2
+	}
+	return plot.Max.Y - 1 - int(math.Round((v-lo)/(hi-lo)*float64(plot.Dy()-1)))
+}
+
+// niceTicks 返回覆盖 [lo, hi] 的约 count 个刻度，刻度间隔为 1、2、5 乘以10的整数次幂，同时返回扩展后的范围。
+func niceTicks(lo, hi float64, count int) (ticks []float64, nlo, nhi float64) {
+	if lo == hi {
+		if lo == 0 {
+			hi = 1
+		} else {
+			lo, hi = lo-math.Abs(lo)/2, hi+math.Abs(hi)/2
+		}
+	}
+
+	raw := (hi - lo) / float64(count)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*mag {
+			step = m * mag
+			break
+		}
+	}
+
+	// 以整数倍计算刻度，避免浮点累加误差（如 3*0.2 得到 0.6000000000000001）
+	tick := func(k float64) float64 {
+		if step < 1 {
+			return k / math.Round(1/step)
+		}
+		return k * step
+	}
+	first, last := math.Floor(lo/step), math.Ceil(hi/step)
+	for k := first; k <= last; k++ {
+		ticks = append(ticks, tick(k))
+	}
+	return ticks, tick(first), tick(last)
+}
+
+// formatValue 格式化刻度值，较大的值使用 k 、 M 、 G 后缀。
+func formatValue(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs >= 1e9:
+		return strconv.FormatFloat(v/1e9, 'f', -1, 64) + "G"
+	case abs >= 1e6:
+		return strconv.FormatFloat(v/1e6, 'f', -1, 64) + "M"
+	case abs >= 1e4:
+		return strconv.FormatFloat(v/1e3, 'f', -1, 64) + "k"
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	draw.Draw(img, image.Rect(x, y, x+w, y+h).Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func hline(img *image.RGBA, x0, x1, y int, c color.Color) {
+	fillRect(img, x0, y, x1-x0, 1, c)
+}
+
+func vline(img *image.RGBA, x, y0, y1 int, c color.Color) {
+	fillRect(img, x, y0, 1, y1-y0, c)
+}
+
+// drawLine 使用 Bresenham 算法绘制指定粗细的线段
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color, thickness int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		fillRect(img, x0-thickness/2, y0-thickness/2, thickness, thickness, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestChartPNG(t *testing.T) {
+	values := []float64{120, 135, 98, math.NaN(), 160, 142, 171, 150}
+	labels := []string{"00:00", "03:00", "06:00", "09:00", "12:00", "15:00", "18:00", "21:00"}
+
+	tests := []struct {
+		name          string
+		chart         Chart
+		width, height int
+	}{
+		{
+			name: "line",
+			chart: Chart{
+				Kind:   Line,
+				Title:  "p99 latency (ms)",
+				Labels: labels,
+				Series: []Series{{Name: "api", Values: values}, {Name: "db", Values: []float64{20, 22, 25, 21, 30, 28, 24, 22}}},
+			},
+			width:  DefaultWidth,
+			height: DefaultHeight,
+		},
+		{
+			name: "bar",
+			chart: Chart{
+				Kind:   Bar,
+				Width:  640,
+				Height: 320,
+				Labels: labels,
+				Series: []Series{{Values: []float64{3, -1, 4, 1, 5, 9, 2, 6}}},
+			},
+			width:  640,
+			height: 320,
+		},
+		{
+			name:   "sparkline",
+			chart:  Chart{Kind: Sparkline, Series: []Series{{Values: values}}},
+			width:  DefaultSparklineWidth,
+			height: DefaultSparklineHeight,
+		},
+		{
+			name:   "constant values",
+			chart:  Chart{Kind: Line, Series: []Series{{Values: []float64{5, 5, 5}}}},
+			width:  DefaultWidth,
+			height: DefaultHeight,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.chart.PNG()
+			if err != nil {
+				t.Fatalf("PNG() error = %v", err)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("DecodeConfig() error = %v", err)
+			}
+			if cfg.Width != tt.width || cfg.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestChartNoData(t *testing.T) {
+	for _, c := range []Chart{
+		{},
+		{Series: []Series{{Values: []float64{math.NaN()}}}},
+	} {
+		if _, err := c.Render(); !errors.Is(err, ErrNoData) {
+			t.Errorf("Render() error = %v, want %v", err, ErrNoData)
+		}
+	}
+}
+
+func TestNiceTicks(t *testing.T) {
+	tests := []struct {
+		lo, hi    float64
+		wantTicks []float64
+	}{
+		{lo: 0, hi: 10, wantTicks: []float64{0, 2, 4, 6, 8, 10}},
+		{lo: 98, hi: 171, wantTicks: []float64{80, 100, 120, 140, 160, 180}},
+		{lo: -1, hi: 9, wantTicks: []float64{-2, 0, 2, 4, 6, 8, 10}},
+		{lo: 0, hi: 0, wantTicks: []float64{0, 0.2, 0.4, 0.6, 0.8, 1}},
+	}
+	for _, tt := range tests {
+		got, _, _ := niceTicks(tt.lo, tt.hi, 5)
+		if !reflect.DeepEqual(got, tt.wantTicks) {
+			t.Errorf("niceTicks(%v, %v) = %v, want %v", tt.lo, tt.hi, got, tt.wantTicks)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[float64]string{
+		0:       "0",
+		0.5:     "0.5",
+		1200:    "1200",
+		25000:   "25k",
+		1500000: "1.5M",
+		-3e9:    "-3G",
+	}
+	for v, want := range tests {
+		if got := formatValue(v); got != want {
+			t.Errorf("formatValue(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
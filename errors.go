@@ -0,0 +1,145 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// 企业微信群机器人接口的常见错误。可通过 errors.Is 判断 *ResError 是否为其中之一（按错误码比较）。
+var (
+	// ErrSystemBusy 系统繁忙
+	ErrSystemBusy = NewResError(-1, "system busy")
+	// ErrInvalidKey webhook key 无效，或机器人已被移出群聊。
+	ErrInvalidKey = NewResError(93000, "invalid webhook url")
+	// ErrRateLimited 接口调用超过频率限制
+	ErrRateLimited = NewResError(45009, "api freq out of limit")
+	// ErrContentTooLong 消息内容超过长度限制
+	ErrContentTooLong = NewResError(45002, "content size out of limit")
+	// ErrInvalidMediaID 无效的 media_id ，如文件已过期。
+	ErrInvalidMediaID = NewResError(40007, "invalid media_id")
+	// ErrImageTooLarge 图片大小超过限制
+	ErrImageTooLarge = NewResError(40009, "invalid image size")
+	// ErrInvalidMsgType 不支持的消息类型
+	ErrInvalidMsgType = NewResError(40008, "invalid message type")
+)
+
+// retryableErrCodes 可重试的企业微信错误码
+var retryableErrCodes = map[int]bool{
+	-1:    true, // 系统繁忙
+	45009: true, // 接口调用超过限制
+	45033: true, // 接口并发调用超过限制
+}
+
+// ResError 响应错误
+type ResError struct {
+	errCode int
+	errMsg  string
+}
+
+// NewResError 返回响应错误实例
+func NewResError(code int, msg string) error {
+	return &ResError{
+		errCode: code,
+		errMsg:  msg,
+	}
+}
+
+// ErrCode 返回错误码
+func (e *ResError) ErrCode() int {
+	return e.errCode
+}
+
+// ErrMsg 返回错误消息内容
+func (e *ResError) ErrMsg() string {
+	return e.errMsg
+}
+
+// Error 返回文本形式的错误描述
+func (e *ResError) Error() string {
+	return fmt.Sprintf("[%d]%s", e.errCode, e.errMsg)
+}
+
+// Is 返回错误码是否与 target 相同，以支持 errors.Is(err, ErrRateLimited) 等用法。
+func (e *ResError) Is(target error) bool {
+	t, ok := target.(*ResError)
+	return ok && t.errCode == e.errCode
+}
+
+// maxErrorBodySize HTTPStatusError 中保留的响应体的最大字节数
+const maxErrorBodySize = 4 << 10
+
+// HTTPStatusError 非2xx的 HTTP 响应错误
+type HTTPStatusError struct {
+	// StatusCode HTTP 状态码
+	StatusCode int
+	// Body 响应体（最多保留4KB）
+	Body []byte
+}
+
+// Error 返回文本形式的错误描述
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("server response abnormality: %d", e.StatusCode)
+}
+
+// IsRetryable 返回错误是否为可重试的临时性错误，如系统繁忙、频率超限、HTTP 429/5xx 以及建立连接失败。
+// 请求发出后的读写错误（如连接被重置、读取响应超时）不可重试，因为服务端可能已收到请求，重试会导致消息重复发送。
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var re *ResError
+	if errors.As(err, &re) {
+		return retryableErrCodes[re.errCode]
+	}
+
+	var se *HTTPStatusError
+	if errors.As(err, &se) {
+		return isRetryableStatus(se.StatusCode)
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// IsPermanent 返回错误是否为重试也无法恢复的永久性错误，如 key 无效、内容超长等服务端明确拒绝的请求，以及消息校验错误、上传文件不满足限制。
+// 对于无法归类的错误（如 context 取消），IsRetryable 与 IsPermanent 均返回 false 。
+func IsPermanent(err error) bool {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return true
+	}
+
+	var (
+		mse *MediaSizeError
+		vfe *VoiceFormatError
+		vde *VoiceDurationError
+	)
+	if errors.As(err, &mse) || errors.As(err, &vfe) || errors.As(err, &vde) {
+		return true
+	}
+
+	var re *ResError
+	if errors.As(err, &re) {
+		return !retryableErrCodes[re.errCode]
+	}
+
+	var se *HTTPStatusError
+	if errors.As(err, &se) {
+		return !isRetryableStatus(se.StatusCode)
+	}
+	return false
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode >= http.StatusInternalServerError
+}
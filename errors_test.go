@@ -0,0 +1,60 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+func TestResError_Is(t *testing.T) {
+	err := fmt.Errorf("send: %w", NewResError(45009, "api freq out of limit, please retry later"))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("errors.Is(%v, ErrRateLimited) = false, want true", err)
+	}
+	if errors.Is(err, ErrInvalidKey) {
+		t.Errorf("errors.Is(%v, ErrInvalidKey) = true, want false", err)
+	}
+
+	var re *ResError
+	if !errors.As(err, &re) || re.ErrCode() != 45009 {
+		t.Errorf("errors.As(%v, *ResError) failed", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+		wantPermanent bool
+	}{
+		{name: "nil", err: nil},
+		{name: "系统繁忙", err: NewResError(-1, "system busy"), wantRetryable: true},
+		{name: "接口调用超过限制", err: ErrRateLimited, wantRetryable: true},
+		{name: "key无效", err: ErrInvalidKey, wantPermanent: true},
+		{name: "HTTP 503", err: &HTTPStatusError{StatusCode: 503}, wantRetryable: true},
+		{name: "HTTP 429", err: &HTTPStatusError{StatusCode: 429}, wantRetryable: true},
+		{name: "HTTP 404", err: &HTTPStatusError{StatusCode: 404}, wantPermanent: true},
+		{name: "建立连接失败", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}}, wantRetryable: true},
+		{name: "连接被拒绝", err: fmt.Errorf("post: %w", syscall.ECONNREFUSED), wantRetryable: true},
+		{name: "读取响应失败", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}},
+		{name: "连接意外断开", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF)},
+		{name: "context取消", err: context.Canceled},
+		{name: "未知错误", err: errors.New("unknown")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := IsPermanent(tt.err); got != tt.wantPermanent {
+				t.Errorf("IsPermanent() = %v, want %v", got, tt.wantPermanent)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package wecombot
+
+// 导出供外部测试包（ wecombot_test ）使用的内部函数
+var (
+	WriteZip    = writeZip
+	VolumeSizes = volumeSizes
+)
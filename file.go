@@ -1,5 +1,12 @@
 package wecombot
 
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
 // FileMessage 文件类型消息。详见 https://developer.work.weixin.qq.com/document/path/91770#%E6%96%87%E4%BB%B6%E7%B1%BB%E5%9E%8B
 type FileMessage struct {
 	// MsgType 必填。消息类型，此时固定为 file 。
@@ -13,18 +20,62 @@ type FileMessage struct {
 
 // SendFileMessage 发送文件消息
 func (bot *Bot) SendFileMessage(msg *FileMessage) (err error) {
+	return bot.SendFileMessageContext(context.Background(), msg)
+}
+
+// SendFileMessageContext 发送文件消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendFileMessageContext(ctx context.Context, msg *FileMessage) (err error) {
 	msg.MsgType = FileMsgType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendFile 发送文件
 func (bot *Bot) SendFile(f []byte, filename string) (err error) {
-	ret, err := bot.UploadMedia(NormalFile, f, filename)
+	return bot.SendFileContext(context.Background(), f, filename)
+}
+
+// SendFileContext 发送文件，可通过 ctx 取消或设置超时（包括文件上传与消息发送两个阶段）。若设置了 media_id 缓存，将复用有效期内相同内容的上传结果。
+func (bot *Bot) SendFileContext(ctx context.Context, f []byte, filename string) (err error) {
+	return bot.sendMedia(ctx, NormalFile, f, filename, func(mediaID string) error {
+		var msg FileMessage
+		msg.File.MediaID = mediaID
+		return bot.SendFileMessageContext(ctx, &msg)
+	})
+}
+
+// SendFileReader 以流的方式上传并发送文件，size 为文件的字节数。
+func (bot *Bot) SendFileReader(r io.Reader, size int64, filename string) (err error) {
+	return bot.SendFileReaderContext(context.Background(), r, size, filename)
+}
+
+// SendFileReaderContext 以流的方式上传并发送文件，可通过 ctx 取消或设置超时（包括文件上传与消息发送两个阶段）。
+func (bot *Bot) SendFileReaderContext(ctx context.Context, r io.Reader, size int64, filename string) (err error) {
+	ret, err := bot.UploadMediaReaderContext(ctx, NormalFile, r, size, filename)
 	if err != nil {
 		return err
 	}
 
 	var msg FileMessage
 	msg.File.MediaID = ret.MediaID
-	return bot.SendFileMessage(&msg)
+	return bot.SendFileMessageContext(ctx, &msg)
+}
+
+// SendFileFromPath 以流的方式上传并发送本地文件，文件名取自路径的最后一个元素。
+func (bot *Bot) SendFileFromPath(path string) (err error) {
+	return bot.SendFileFromPathContext(context.Background(), path)
+}
+
+// SendFileFromPathContext 以流的方式上传并发送本地文件，可通过 ctx 取消或设置超时（包括文件上传与消息发送两个阶段）。
+func (bot *Bot) SendFileFromPathContext(ctx context.Context, path string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return bot.SendFileReaderContext(ctx, f, fi.Size(), filepath.Base(path))
 }
@@ -1,6 +1,7 @@
 package wecombot
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
@@ -21,16 +22,34 @@ type ImageMessage struct {
 
 // SendImageMessage 发送图片消息
 func (bot *Bot) SendImageMessage(msg *ImageMessage) (err error) {
+	return bot.SendImageMessageContext(context.Background(), msg)
+}
+
+// SendImageMessageContext 发送图片消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendImageMessageContext(ctx context.Context, msg *ImageMessage) (err error) {
 	msg.MsgType = ImageMsgType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendImageBytes 发送图片消息
 func (bot *Bot) SendImage(img []byte) (err error) {
+	return bot.SendImageContext(context.Background(), img)
+}
+
+// SendImageContext 发送图片消息，可通过 ctx 取消或设置超时。若开启了图片规范化，图片会先经 NormalizeImage 处理。
+func (bot *Bot) SendImageContext(ctx context.Context, img []byte) (err error) {
+	if bot.imageOpts != nil {
+		one, err := NormalizeImage(img, bot.imageOpts)
+		if err != nil {
+			return err
+		}
+		return bot.SendImageMessageContext(ctx, one.Message())
+	}
+
 	sum := md5.Sum(img)
 
 	var msg ImageMessage
 	msg.Image.Md5 = hex.EncodeToString(sum[:])
 	msg.Image.Base64 = base64.StdEncoding.EncodeToString(img)
-	return bot.SendImageMessage(&msg)
+	return bot.SendImageMessageContext(ctx, &msg)
 }
@@ -0,0 +1,248 @@
+package wecombot
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	"image/png"
+)
+
+// ErrImageNotFit 图片在压缩到最低质量及最小尺寸后仍超出大小限制
+var ErrImageNotFit = errors.New("image does not fit the size limit")
+
+// ErrImageTooManyPixels 需要解码的图片像素数超出限制
+var ErrImageTooManyPixels = errors.New("image exceeds the pixel limit")
+
+// DefaultMaxImagePixels 默认允许解码的最大像素数（约4000万像素，解码后约占用160MB内存）
+const DefaultMaxImagePixels = 40 << 20
+
+const (
+	defaultJPEGQuality    = 85
+	defaultJPEGMinQuality = 40
+	jpegQualityStep       = 10
+	// minImageSide 逐步缩小图片时允许的最短边长（像素）
+	minImageSide = 16
+)
+
+// ImageOptions 图片规范化选项
+type ImageOptions struct {
+	// MaxBytes 图片的最大字节数，为0或超过 MaxImageBytes 时使用 MaxImageBytes 。
+	MaxBytes int
+	// MaxWidth 图片的最大宽度（像素），为0时不限制。
+	MaxWidth int
+	// MaxHeight 图片的最大高度（像素），为0时不限制。
+	MaxHeight int
+	// Quality JPEG 编码的初始质量（1-100），为0时使用85。
+	Quality int
+	// MinQuality JPEG 编码的最低质量（1-100），为0时使用40。
+	MinQuality int
+	// MaxPixels 允许解码的最大像素数（宽×高），为0时使用 DefaultMaxImagePixels 。
+	// 图片在解码前按照文件头声明的尺寸校验，避免声明了超大画布的小文件耗尽内存。
+	MaxPixels int64
+}
+
+// NormalizedImage 规范化后的图片
+type NormalizedImage struct {
+	// Data 图片内容
+	Data []byte
+	// Md5 图片内容的md5值
+	Md5 string
+	// Format 图片格式，png 或 jpeg 。
+	Format string
+	// Width 图片宽度（像素）
+	Width int
+	// Height 图片高度（像素）
+	Height int
+}
+
+// Message 返回以该图片为内容的图片消息
+func (img *NormalizedImage) Message() *ImageMessage {
+	var msg ImageMessage
+	msg.MsgType = ImageMsgType
+	msg.Image.Md5 = img.Md5
+	msg.Image.Base64 = base64.StdEncoding.EncodeToString(img.Data)
+	return &msg
+}
+
+// WithImageNormalization 开启图片规范化。开启后 SendImage 会先调用 NormalizeImage 处理图片，opts 为 nil 时使用默认选项。
+func WithImageNormalization(opts *ImageOptions) func(*Bot) {
+	if opts == nil {
+		opts = new(ImageOptions)
+	}
+	return func(bot *Bot) {
+		bot.imageOpts = opts
+	}
+}
+
+func (opts *ImageOptions) normalize() ImageOptions {
+	one := *opts
+	if one.MaxBytes <= 0 || one.MaxBytes > MaxImageBytes {
+		one.MaxBytes = MaxImageBytes
+	}
+	if one.Quality <= 0 || one.Quality > 100 {
+		one.Quality = defaultJPEGQuality
+	}
+	if one.MaxPixels <= 0 {
+		one.MaxPixels = DefaultMaxImagePixels
+	}
+	if one.MinQuality <= 0 || one.MinQuality > one.Quality {
+		one.MinQuality = defaultJPEGMinQuality
+		if one.MinQuality > one.Quality {
+			one.MinQuality = one.Quality
+		}
+	}
+	return one
+}
+
+// NormalizeImage 将图片处理为企业微信图片消息可接受的形式：识别图片格式，将 JPG/PNG 以外的格式（如 GIF ，取第一帧）转换为 PNG 或 JPEG ，
+// 按最大宽高等比缩小，并在超出大小限制时以逐步降低的质量重新编码为 JPEG ，必要时进一步缩小尺寸。
+// 已满足要求的 JPG/PNG 图片将原样返回。
+func NormalizeImage(data []byte, opts *ImageOptions) (*NormalizedImage, error) {
+	if opts == nil {
+		opts = new(ImageOptions)
+	}
+	o := opts.normalize()
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("detect image format: %w", err)
+	}
+
+	w, h := fitSize(cfg.Width, cfg.Height, o.MaxWidth, o.MaxHeight)
+	if (format == "jpeg" || format == "png") && len(data) <= o.MaxBytes && w == cfg.Width && h == cfg.Height {
+		return newNormalizedImage(data, format, cfg.Width, cfg.Height), nil
+	}
+
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > o.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooManyPixels, cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	for {
+		img := src
+		if b := src.Bounds(); b.Dx() != w || b.Dy() != h {
+			img = scaleImage(src, w, h)
+		}
+
+		// 无损格式优先保留为 PNG
+		if format != "jpeg" {
+			var buf bytes.Buffer
+			enc := png.Encoder{CompressionLevel: png.BestCompression}
+			if err = enc.Encode(&buf, img); err != nil {
+				return nil, err
+			}
+			if buf.Len() <= o.MaxBytes {
+				return newNormalizedImage(buf.Bytes(), "png", w, h), nil
+			}
+		}
+
+		opaque := flatten(img)
+		for quality := o.Quality; ; quality -= jpegQualityStep {
+			if quality < o.MinQuality {
+				quality = o.MinQuality
+			}
+			var buf bytes.Buffer
+			if err = jpeg.Encode(&buf, opaque, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, err
+			}
+			if buf.Len() <= o.MaxBytes {
+				return newNormalizedImage(buf.Bytes(), "jpeg", w, h), nil
+			}
+			if quality == o.MinQuality {
+				break
+			}
+		}
+
+		if w*3/4 < minImageSide || h*3/4 < minImageSide {
+			return nil, ErrImageNotFit
+		}
+		w, h = w*3/4, h*3/4
+	}
+}
+
+func newNormalizedImage(data []byte, format string, w, h int) *NormalizedImage {
+	sum := md5.Sum(data)
+	return &NormalizedImage{
+		Data:   data,
+		Md5:    hex.EncodeToString(sum[:]),
+		Format: format,
+		Width:  w,
+		Height: h,
+	}
+}
+
+// fitSize 返回按最大宽高等比缩小后的尺寸
+func fitSize(w, h, maxW, maxH int) (int, int) {
+	if maxW > 0 && w > maxW {
+		h = h * maxW / w
+		w = maxW
+	}
+	if maxH > 0 && h > maxH {
+		w = w * maxH / h
+		h = maxH
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
+
+// scaleImage 使用区域平均法将图片缩放为 w*h
+func scaleImage(src image.Image, w, h int) *image.RGBA {
+	sb := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := sb.Dx(), sb.Dy()
+
+	for y := 0; y < h; y++ {
+		y0 := sb.Min.Y + y*sh/h
+		y1 := sb.Min.Y + (y+1)*sh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := sb.Min.X + x*sw/w
+			x1 := sb.Min.X + (x+1)*sw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// flatten 将图片合成到白色背景上，以便编码为不支持透明度的 JPEG 。
+func flatten(src image.Image) image.Image {
+	if o, ok := src.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return src
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, b, src, b.Min, draw.Over)
+	return dst
+}
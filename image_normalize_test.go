@@ -0,0 +1,87 @@
+package wecombot
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+func TestNormalizeImage(t *testing.T) {
+	// 随机噪点图片难以压缩，可用于触发降质及缩小尺寸。
+	noise := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	rnd := rand.New(rand.NewSource(1))
+	for i := range noise.Pix {
+		noise.Pix[i] = uint8(rnd.Intn(256))
+	}
+	var noisePNG bytes.Buffer
+	if err := png.Encode(&noisePNG, noise); err != nil {
+		t.Fatal(err)
+	}
+
+	palette := image.NewPaletted(image.Rect(0, 0, 64, 32), color.Palette{color.White, color.Black})
+	var smallGIF bytes.Buffer
+	if err := gif.Encode(&smallGIF, palette, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		opts       *ImageOptions
+		wantFormat string
+		wantWidth  int
+		wantSame   bool
+	}{
+		{name: "符合要求的PNG原样返回", data: noisePNG.Bytes(), wantFormat: "png", wantWidth: 400, wantSame: true},
+		{name: "GIF转换为PNG", data: smallGIF.Bytes(), wantFormat: "png", wantWidth: 64},
+		{name: "按最大宽度缩小", data: noisePNG.Bytes(), opts: &ImageOptions{MaxWidth: 200}, wantFormat: "png", wantWidth: 200},
+		{name: "超出大小限制时转为JPEG", data: noisePNG.Bytes(), opts: &ImageOptions{MaxBytes: 64 << 10}, wantFormat: "jpeg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeImage(tt.data, tt.opts)
+			if err != nil {
+				t.Fatalf("NormalizeImage() error = %v", err)
+			}
+			if got.Format != tt.wantFormat {
+				t.Errorf("NormalizeImage().Format = %v, want %v", got.Format, tt.wantFormat)
+			}
+			if tt.wantWidth != 0 && got.Width != tt.wantWidth {
+				t.Errorf("NormalizeImage().Width = %v, want %v", got.Width, tt.wantWidth)
+			}
+			if tt.opts != nil && tt.opts.MaxBytes != 0 && len(got.Data) > tt.opts.MaxBytes {
+				t.Errorf("len(NormalizeImage().Data) = %d, want <= %d", len(got.Data), tt.opts.MaxBytes)
+			}
+			if same := bytes.Equal(got.Data, tt.data); same != tt.wantSame {
+				t.Errorf("NormalizeImage() returns original data = %v, want %v", same, tt.wantSame)
+			}
+			if err = got.Message().Validate(); err != nil {
+				t.Errorf("NormalizeImage().Message().Validate() error = %v", err)
+			}
+		})
+	}
+
+	if _, err := NormalizeImage([]byte("not an image"), nil); err == nil {
+		t.Error("NormalizeImage() with invalid data should return an error")
+	}
+}
+
+func TestNormalizeImage_TooManyPixels(t *testing.T) {
+	palette := image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.White, color.Black})
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, palette, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 将 GIF 逻辑屏幕尺寸改写为 65535x65535 ，文件本身仍只有几十字节。
+	data := buf.Bytes()
+	copy(data[6:10], []byte{0xff, 0xff, 0xff, 0xff})
+
+	if _, err := NormalizeImage(data, nil); !errors.Is(err, ErrImageTooManyPixels) {
+		t.Errorf("NormalizeImage() error = %v, want %v", err, ErrImageTooManyPixels)
+	}
+}
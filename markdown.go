@@ -1,5 +1,7 @@
 package wecombot
 
+import "context"
+
 // MarkdownMessage Markdown 类型消息。详见 https://developer.work.weixin.qq.com/document/path/91770#markdown%E7%B1%BB%E5%9E%8B
 type MarkdownMessage struct {
 	// MsgType 必填。消息类型，此时固定为 markdown 。
@@ -13,13 +15,23 @@ type MarkdownMessage struct {
 
 // SendMarkdownMessage 发送 Markdown 消息
 func (bot *Bot) SendMarkdownMessage(msg *MarkdownMessage) (err error) {
+	return bot.SendMarkdownMessageContext(context.Background(), msg)
+}
+
+// SendMarkdownMessageContext 发送 Markdown 消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendMarkdownMessageContext(ctx context.Context, msg *MarkdownMessage) (err error) {
 	msg.MsgType = MarkdownMsgType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendMarkdown 发送 Markdown 消息
 func (bot *Bot) SendMarkdown(content string) (err error) {
+	return bot.SendMarkdownContext(context.Background(), content)
+}
+
+// SendMarkdownContext 发送 Markdown 消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendMarkdownContext(ctx context.Context, content string) (err error) {
 	var msg MarkdownMessage
 	msg.Markdown.Content = content
-	return bot.SendMarkdownMessage(&msg)
+	return bot.SendMarkdownMessageContext(ctx, &msg)
 }
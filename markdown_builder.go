@@ -0,0 +1,197 @@
+package wecombot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FontColor Markdown 消息支持的字体颜色
+type FontColor string
+
+const (
+	// FontInfo 绿色
+	FontInfo FontColor = "info"
+	// FontComment 灰色
+	FontComment FontColor = "comment"
+	// FontWarning 橙红色
+	FontWarning FontColor = "warning"
+)
+
+// markdownEscaper 将 Markdown 消息中可能触发语法的字符替换为外观相近的全角字符。
+// 企业微信的 markdown 类型仅支持文档列出的语法子集（标题 # 、加粗 ** 、链接 [](...) 、行内代码 ` 、引用 > 、
+// <font> 及 <@userid> ），且不支持反斜杠转义，反斜杠会原样显示（详见 https://developer.work.weixin.qq.com/document/path/91770#markdown%E7%B1%BB%E5%9E%8B ）。
+// 因此只处理上述语法用到的字符， _ 、 \ 等不属于该子集的字符保持不变。
+var markdownEscaper = strings.NewReplacer(
+	"*", "＊",
+	"`", "｀",
+	"[", "［",
+	"]", "］",
+	"<", "＜",
+	">", "＞",
+	"#", "＃",
+)
+
+// linkURLEscaper 转义链接地址中会破坏 Markdown 链接语法的字符
+var linkURLEscaper = strings.NewReplacer(
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+	"\n", "",
+	"\r", "",
+)
+
+// EscapeMarkdown 将 s 中可能触发企业微信 Markdown 语法的字符（ * ` [ ] < > # ）替换为外观相近的全角字符，使其不被解析为 Markdown 。
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// MarkdownBuilder 用于构建企业微信支持的 Markdown 子集（标题、加粗、链接、行内代码、引用、字体颜色、@成员）。
+// 所有传入的文本均会经 EscapeMarkdown 处理，构建过程中可随时通过 Len 、 Remaining 检查内容长度是否超出上限。
+type MarkdownBuilder struct {
+	buf strings.Builder
+}
+
+// NewMarkdownBuilder 返回 Markdown 构建器
+func NewMarkdownBuilder() *MarkdownBuilder {
+	return new(MarkdownBuilder)
+}
+
+// lineStart 确保后续内容从新的一行开始
+func (b *MarkdownBuilder) lineStart() {
+	if s := b.buf.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		b.buf.WriteByte('\n')
+	}
+}
+
+// Text 追加普通文本
+func (b *MarkdownBuilder) Text(s string) *MarkdownBuilder {
+	b.buf.WriteString(EscapeMarkdown(s))
+	return b
+}
+
+// Raw 追加未经转义的原始 Markdown 内容
+func (b *MarkdownBuilder) Raw(s string) *MarkdownBuilder {
+	b.buf.WriteString(s)
+	return b
+}
+
+// Newline 追加换行
+func (b *MarkdownBuilder) Newline() *MarkdownBuilder {
+	b.buf.WriteByte('\n')
+	return b
+}
+
+// Heading 追加独占一行的标题，level 取值范围为1-6。
+func (b *MarkdownBuilder) Heading(level int, s string) *MarkdownBuilder {
+	if level < 1 {
+		level = 1
+	} else if level > 6 {
+		level = 6
+	}
+	b.lineStart()
+	b.buf.WriteString(strings.Repeat("#", level))
+	b.buf.WriteByte(' ')
+	b.buf.WriteString(EscapeMarkdown(singleLine(s)))
+	b.buf.WriteByte('\n')
+	return b
+}
+
+// Bold 追加加粗文本
+func (b *MarkdownBuilder) Bold(s string) *MarkdownBuilder {
+	b.buf.WriteString("**")
+	b.buf.WriteString(EscapeMarkdown(s))
+	b.buf.WriteString("**")
+	return b
+}
+
+// Link 追加链接
+func (b *MarkdownBuilder) Link(text, url string) *MarkdownBuilder {
+	fmt.Fprintf(&b.buf, "[%s](%s)", EscapeMarkdown(singleLine(text)), linkURLEscaper.Replace(url))
+	return b
+}
+
+// Code 追加行内代码。行内代码中的内容不会被转义，其中的换行会被替换为空格，反引号会被替换为全角的 ｀ 。
+func (b *MarkdownBuilder) Code(s string) *MarkdownBuilder {
+	b.buf.WriteString(inlineCode(singleLine(s)))
+	return b
+}
+
+// Quote 追加引用，多行文本的每一行都会作为引用。
+func (b *MarkdownBuilder) Quote(s string) *MarkdownBuilder {
+	b.lineStart()
+	for _, line := range strings.Split(s, "\n") {
+		b.buf.WriteString("> ")
+		b.buf.WriteString(EscapeMarkdown(line))
+		b.buf.WriteByte('\n')
+	}
+	return b
+}
+
+// Font 追加指定颜色的文本
+func (b *MarkdownBuilder) Font(color FontColor, s string) *MarkdownBuilder {
+	fmt.Fprintf(&b.buf, `<font color="%s">%s</font>`, color, EscapeMarkdown(s))
+	return b
+}
+
+// Info 追加绿色文本
+func (b *MarkdownBuilder) Info(s string) *MarkdownBuilder {
+	return b.Font(FontInfo, s)
+}
+
+// Comment 追加灰色文本
+func (b *MarkdownBuilder) Comment(s string) *MarkdownBuilder {
+	return b.Font(FontComment, s)
+}
+
+// Warning 追加橙红色文本
+func (b *MarkdownBuilder) Warning(s string) *MarkdownBuilder {
+	return b.Font(FontWarning, s)
+}
+
+// Mention 追加@群成员，userid 为成员的 userid 。
+func (b *MarkdownBuilder) Mention(userid string) *MarkdownBuilder {
+	userid = strings.NewReplacer("<", "", ">", "", "\n", "").Replace(userid)
+	fmt.Fprintf(&b.buf, "<@%s>", userid)
+	return b
+}
+
+// Len 返回当前内容的字节数
+func (b *MarkdownBuilder) Len() int {
+	return b.buf.Len()
+}
+
+// Remaining 返回距离 Markdown 消息长度上限的剩余字节数，超出上限时为负数。
+func (b *MarkdownBuilder) Remaining() int {
+	return MaxMarkdownBytes - b.buf.Len()
+}
+
+// Overflow 返回当前内容是否超出 Markdown 消息的长度上限
+func (b *MarkdownBuilder) Overflow() bool {
+	return b.Remaining() < 0
+}
+
+// String 返回当前内容
+func (b *MarkdownBuilder) String() string {
+	return b.buf.String()
+}
+
+// Reset 清空当前内容
+func (b *MarkdownBuilder) Reset() *MarkdownBuilder {
+	b.buf.Reset()
+	return b
+}
+
+// Message 返回可直接用于 SendMarkdownMessage 的 Markdown 消息。内容为空或超出长度上限时返回 *ValidationError 。
+func (b *MarkdownBuilder) Message() (*MarkdownMessage, error) {
+	var msg MarkdownMessage
+	msg.MsgType = MarkdownMsgType
+	msg.Markdown.Content = b.String()
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,42 @@
+package wecombot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownBuilder(t *testing.T) {
+	b := NewMarkdownBuilder().
+		Heading(1, "成绩单").
+		Bold("姓名：").Text("张_三").Newline().
+		Bold("数学：").Info("97").Newline().
+		Link("详情 [pdf]", "https://example.com/a b").
+		Quote("请 <@all> 查收").
+		Text("cmd: ").Code("go test ./...").Mention("zhangsan").Newline().
+		Text(`C:\logs\*.log`).Code("a`b")
+
+	want := "# 成绩单\n" +
+		"**姓名：**张_三\n" +
+		"**数学：**<font color=\"info\">97</font>\n" +
+		"[详情 ［pdf］](https://example.com/a%20b)\n" +
+		"> 请 ＜@all＞ 查收\n" +
+		"cmd: `go test ./...`<@zhangsan>\n" +
+		"C:\\logs\\＊.log`a｀b`"
+	if got := b.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if b.Len() != len(want) || b.Remaining() != MaxMarkdownBytes-len(want) {
+		t.Errorf("Len() = %d, Remaining() = %d", b.Len(), b.Remaining())
+	}
+	if _, err := b.Message(); err != nil {
+		t.Errorf("Message() error = %v", err)
+	}
+
+	b.Text(strings.Repeat("a", MaxMarkdownBytes))
+	if !b.Overflow() {
+		t.Error("Overflow() = false, want true")
+	}
+	if _, err := b.Message(); err == nil {
+		t.Error("Message() should fail when content overflows")
+	}
+}
@@ -0,0 +1,315 @@
+package wecombot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ConversionLoss 转换过程中被降级或丢弃的内容
+type ConversionLoss struct {
+	// Line 所在行号（从1开始）
+	Line int
+	// Construct 语法结构，如 table 、 strikethrough 。
+	Construct string
+	// Detail 处理方式说明
+	Detail string
+}
+
+// String 返回文本形式的描述
+func (l *ConversionLoss) String() string {
+	return fmt.Sprintf("line %d: %s %s", l.Line, l.Construct, l.Detail)
+}
+
+// ConvertResult Markdown 转换结果
+type ConvertResult struct {
+	// Content 转换后的企业微信 Markdown 内容
+	Content string
+	// Losses 转换过程中被降级或丢弃的内容
+	Losses []*ConversionLoss
+}
+
+// Message 返回以转换结果为内容的 Markdown 消息
+func (r *ConvertResult) Message() *MarkdownMessage {
+	var msg MarkdownMessage
+	msg.MsgType = MarkdownMsgType
+	msg.Markdown.Content = r.Content
+	return &msg
+}
+
+var (
+	reFence       = regexp.MustCompile("^\\s*(```+|~~~+)")
+	reHeading     = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	reRule        = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	reListItem    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	reTaskItem    = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	reQuote       = regexp.MustCompile(`^\s{0,3}((?:>\s?)+)(.*)$`)
+	reTableDelim  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	reLinkDef     = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*<?(\S+?)>?(?:\s+["'(].*["')])?\s*$`)
+	reCodeSpan    = regexp.MustCompile("`+[^`]*`+")
+	reImage       = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+["'(][^)]*["')])?\s*\)`)
+	reLinkTitle   = regexp.MustCompile(`\[([^\]]*)\]\(\s*<?([^)\s>]+)>?\s+["'(][^)]*["')]\s*\)`)
+	reRefLink     = regexp.MustCompile(`\[([^\]]+)\]\[([^\]]*)\]`)
+	reAutolink    = regexp.MustCompile(`<((?:https?|ftp)://[^>\s]+)>`)
+	reStrike      = regexp.MustCompile(`~~([^~\n]+)~~`)
+	reBoldUnder   = regexp.MustCompile(`__([^_\n]+)__`)
+	reItalicStar  = regexp.MustCompile(`\*([^*\n]+)\*`)
+	reItalicUnder = regexp.MustCompile(`(^|[^\w\\])_([^_\n]+)_(\W|$)`)
+	reHTMLBreak   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	reHTMLTag     = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	reKeptHTMLTag = regexp.MustCompile(`^(?i:<font\s[^>]*>|</font>)$|^<@[^>]+>$`)
+)
+
+// boldPlaceholder 转换斜体时用于暂存加粗标记的占位符
+const boldPlaceholder = "\x00"
+
+type markdownConverter struct {
+	lines  []string
+	refs   map[string]string
+	out    []string
+	losses []*ConversionLoss
+}
+
+// ConvertMarkdown 将 CommonMark/GFM 格式的 Markdown 转换为企业微信 Markdown 消息支持的语法子集，
+// 不支持的语法会被降级为最接近的形式：表格转为键值对行，图片转为链接，（嵌套）列表展平为项目符号，
+// 代码块转为逐行的行内代码，删除线、斜体、分割线及多余的 HTML 标签被移除。所有降级均记录在 Losses 中。
+func ConvertMarkdown(src string) *ConvertResult {
+	c := markdownConverter{
+		lines: strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"),
+		refs:  make(map[string]string),
+	}
+	c.collectRefs()
+	c.convert()
+
+	return &ConvertResult{
+		Content: strings.TrimSpace(strings.Join(c.out, "\n")),
+		Losses:  c.losses,
+	}
+}
+
+func (c *markdownConverter) lose(line int, construct, format string, args ...interface{}) {
+	c.losses = append(c.losses, &ConversionLoss{Line: line + 1, Construct: construct, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (c *markdownConverter) emit(s string) {
+	if s == "" && (len(c.out) == 0 || c.out[len(c.out)-1] == "") {
+		return // 合并连续的空行
+	}
+	c.out = append(c.out, s)
+}
+
+// collectRefs 收集引用式链接的定义，并将定义行置空。
+func (c *markdownConverter) collectRefs() {
+	inFence := false
+	for i, line := range c.lines {
+		if reFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := reLinkDef.FindStringSubmatch(line); m != nil {
+			c.refs[strings.ToLower(m[1])] = m[2]
+			c.lines[i] = ""
+		}
+	}
+}
+
+func (c *markdownConverter) convert() {
+	for i := 0; i < len(c.lines); i++ {
+		line := c.lines[i]
+
+		if m := reFence.FindStringSubmatch(line); m != nil {
+			i = c.convertFence(i, strings.TrimSpace(m[1])[:3])
+			continue
+		}
+		if i+1 < len(c.lines) && strings.Contains(line, "|") && reTableDelim.MatchString(c.lines[i+1]) && strings.Contains(c.lines[i+1], "-") {
+			i = c.convertTable(i)
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			c.emit("")
+			continue
+		}
+		if reRule.MatchString(line) {
+			c.lose(i, "horizontal rule", "removed")
+			c.emit("")
+			continue
+		}
+		if m := reHeading.FindStringSubmatch(line); m != nil {
+			c.emit(m[1] + " " + c.inline(i, m[2]))
+			continue
+		}
+		if m := reQuote.FindStringSubmatch(line); m != nil {
+			if strings.Count(m[1], ">") > 1 {
+				c.lose(i, "nested blockquote", "flattened")
+			}
+			c.emit("> " + c.inline(i, m[2]))
+			continue
+		}
+		if m := reListItem.FindStringSubmatch(line); m != nil {
+			c.emit(c.convertListItem(i, m[1], m[2], m[3]))
+			continue
+		}
+		c.emit(c.inline(i, strings.TrimSpace(line)))
+	}
+}
+
+// convertFence 将代码块转为逐行的行内代码，返回代码块结束行的下标。
+func (c *markdownConverter) convertFence(start int, fence string) int {
+	c.lose(start, "fenced code block", "converted to inline code lines")
+	i := start + 1
+	for ; i < len(c.lines); i++ {
+		line := c.lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		c.emit(inlineCode(strings.TrimRight(line, " \t")))
+	}
+	return i
+}
+
+// convertTable 将表格的每一行转为“表头: 单元格”形式的键值对行，返回表格最后一行的下标。
+func (c *markdownConverter) convertTable(start int) int {
+	c.lose(start, "table", "converted to key/value lines")
+	header := splitTableRow(c.lines[start])
+
+	i := start + 2
+	for ; i < len(c.lines); i++ {
+		line := c.lines[i]
+		if strings.TrimSpace(line) == "" || !strings.Contains(line, "|") {
+			break
+		}
+		c.emit("")
+		for j, cell := range splitTableRow(line) {
+			key := fmt.Sprintf("列%d", j+1)
+			if j < len(header) && header[j] != "" {
+				key = header[j]
+			}
+			c.emit(fmt.Sprintf("**%s**: %s", c.inline(i, key), c.inline(i, cell)))
+		}
+	}
+	c.emit("")
+	return i - 1
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func (c *markdownConverter) convertListItem(i int, indent, marker, text string) string {
+	if len(strings.ReplaceAll(indent, "\t", "    ")) >= 2 {
+		c.lose(i, "nested list", "flattened")
+	}
+	if m := reTaskItem.FindStringSubmatch(text); m != nil {
+		box := "☐"
+		if m[1] != " " {
+			box = "☑"
+		}
+		text = box + " " + text[len(m[0]):]
+	}
+
+	bullet := "•"
+	if marker[0] >= '0' && marker[0] <= '9' {
+		bullet = strings.TrimRight(marker, ".)") + "."
+	}
+	return bullet + " " + c.inline(i, text)
+}
+
+// inline 转换行内语法，行内代码中的内容保持不变。
+func (c *markdownConverter) inline(i int, s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range reCodeSpan.FindAllStringIndex(s, -1) {
+		b.WriteString(c.inlineText(i, s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(c.inlineText(i, s[last:]))
+	return b.String()
+}
+
+func (c *markdownConverter) inlineText(i int, s string) string {
+	if s == "" {
+		return s
+	}
+
+	if reImage.MatchString(s) {
+		c.lose(i, "image", "converted to link")
+		s = reImage.ReplaceAllStringFunc(s, func(m string) string {
+			sub := reImage.FindStringSubmatch(m)
+			alt := sub[1]
+			if alt == "" {
+				alt = "图片"
+			}
+			return fmt.Sprintf("[%s](%s)", alt, sub[2])
+		})
+	}
+	s = reLinkTitle.ReplaceAllString(s, "[$1]($2)")
+	s = reRefLink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := reRefLink.FindStringSubmatch(m)
+		ref := sub[2]
+		if ref == "" {
+			ref = sub[1]
+		}
+		if url, ok := c.refs[strings.ToLower(ref)]; ok {
+			return fmt.Sprintf("[%s](%s)", sub[1], url)
+		}
+		return m
+	})
+	s = reAutolink.ReplaceAllString(s, "[$1]($1)")
+
+	if reStrike.MatchString(s) {
+		c.lose(i, "strikethrough", "removed")
+		s = reStrike.ReplaceAllString(s, "$1")
+	}
+
+	s = reBoldUnder.ReplaceAllString(s, "**$1**")
+	s = strings.ReplaceAll(s, "**", boldPlaceholder)
+	if reItalicStar.MatchString(s) || reItalicUnder.MatchString(s) {
+		c.lose(i, "emphasis", "removed")
+		s = reItalicStar.ReplaceAllString(s, "$1")
+		s = reItalicUnder.ReplaceAllString(s, "$1$2$3")
+	}
+	s = strings.ReplaceAll(s, boldPlaceholder, "**")
+
+	s = reHTMLBreak.ReplaceAllString(s, "\n")
+	s = reHTMLTag.ReplaceAllStringFunc(s, func(tag string) string {
+		if reKeptHTMLTag.MatchString(tag) {
+			return tag
+		}
+		c.lose(i, "html", "tag %s removed", tag)
+		return ""
+	})
+	return s
+}
+
+// inlineCode 返回以行内代码形式展示的 s 。企业微信 Markdown 仅支持以单个反引号包裹的行内代码，
+// 不支持以多个反引号包裹，因此将 s 中的反引号替换为全角的 ｀ 。
+func inlineCode(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "｀") + "`"
+}
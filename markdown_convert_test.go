@@ -0,0 +1,56 @@
+package wecombot
+
+import "testing"
+
+func TestConvertMarkdown(t *testing.T) {
+	src := "# 构建报告\n" +
+		"\n" +
+		"状态：<font color=\"info\">成功</font>，耗时 __3m__，~~旧版本~~ *已废弃*\n" +
+		"\n" +
+		"| 模块 | 结果 |\n" +
+		"|:---|---:|\n" +
+		"| api | 通过 |\n" +
+		"| web | 失败 |\n" +
+		"\n" +
+		"- 变更\n" +
+		"  - [x] 修复 bug\n" +
+		"1. 详见 ![截图](https://example.com/a.png) 与 [日志][log]\n" +
+		"\n" +
+		"---\n" +
+		"```sh\n" +
+		"make test\n" +
+		"```\n" +
+		"\n" +
+		"[log]: https://ci.example.com/1 \"构建日志\"\n"
+
+	want := "# 构建报告\n" +
+		"\n" +
+		"状态：<font color=\"info\">成功</font>，耗时 **3m**，旧版本 已废弃\n" +
+		"\n" +
+		"**模块**: api\n" +
+		"**结果**: 通过\n" +
+		"\n" +
+		"**模块**: web\n" +
+		"**结果**: 失败\n" +
+		"\n" +
+		"• 变更\n" +
+		"• ☑ 修复 bug\n" +
+		"1. 详见 [截图](https://example.com/a.png) 与 [日志](https://ci.example.com/1)\n" +
+		"\n" +
+		"`make test`"
+
+	got := ConvertMarkdown(src)
+	if got.Content != want {
+		t.Errorf("ConvertMarkdown().Content = %q, want %q", got.Content, want)
+	}
+
+	constructs := make(map[string]bool)
+	for _, one := range got.Losses {
+		constructs[one.Construct] = true
+	}
+	for _, construct := range []string{"table", "strikethrough", "emphasis", "nested list", "image", "horizontal rule", "fenced code block"} {
+		if !constructs[construct] {
+			t.Errorf("ConvertMarkdown().Losses does not report %s", construct)
+		}
+	}
+}
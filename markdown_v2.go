@@ -0,0 +1,165 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MarkdownV2Message Markdown V2 类型消息，在 Markdown 类型的基础上支持表格、有序及无序列表、图片、分割线及代码块等语法。
+type MarkdownV2Message struct {
+	// MsgType 必填。消息类型，此时固定为 markdown_v2 。
+	MsgType MsgType `json:"msgtype"`
+	// 消息内容
+	MarkdownV2 struct {
+		// Content 必填。markdown_v2内容，最长不超过4096个字节，必须是utf8编码。
+		Content string `json:"content"`
+	} `json:"markdown_v2"`
+}
+
+// WithMarkdownV2Fallback 设置在 markdown_v2 消息被服务端拒绝（如私有化部署版本过低）时，
+// 将内容通过 ConvertMarkdown 降级转换后改用 markdown 类型消息发送，超长时自动拆分为多条消息。
+func WithMarkdownV2Fallback() func(*Bot) {
+	return func(bot *Bot) {
+		bot.markdownV2Fallback = true
+	}
+}
+
+// SendMarkdownV2Message 发送 Markdown V2 消息
+func (bot *Bot) SendMarkdownV2Message(msg *MarkdownV2Message) (err error) {
+	return bot.SendMarkdownV2MessageContext(context.Background(), msg)
+}
+
+// SendMarkdownV2MessageContext 发送 Markdown V2 消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendMarkdownV2MessageContext(ctx context.Context, msg *MarkdownV2Message) (err error) {
+	msg.MsgType = MarkdownV2MsgType
+	err = bot.send(ctx, msg)
+	if err == nil || !bot.markdownV2Fallback || !errors.Is(err, ErrInvalidMsgType) {
+		return err
+	}
+	return bot.SendMarkdownSplitContext(ctx, ConvertMarkdown(msg.MarkdownV2.Content).Content, SplitOptions{Numbered: true})
+}
+
+// SendMarkdownV2 发送 Markdown V2 消息
+func (bot *Bot) SendMarkdownV2(content string) (err error) {
+	return bot.SendMarkdownV2Context(context.Background(), content)
+}
+
+// SendMarkdownV2Context 发送 Markdown V2 消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendMarkdownV2Context(ctx context.Context, content string) (err error) {
+	var msg MarkdownV2Message
+	msg.MarkdownV2.Content = content
+	return bot.SendMarkdownV2MessageContext(ctx, &msg)
+}
+
+// tableCellEscaper 转义表格单元格中会破坏表格语法的字符
+var tableCellEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// MarkdownTable 将二维表格渲染为 Markdown V2 表格，第一行为表头。
+func MarkdownTable(rows [][]string) string {
+	if len(rows) == 0 {
+		return ""
+	}
+
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for i := 0; i < cols; i++ {
+			var cell string
+			if i < len(row) {
+				cell = tableCellEscaper.Replace(row[i])
+			}
+			b.WriteString(" ")
+			b.WriteString(cell)
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(rows[0])
+	b.WriteString("|")
+	b.WriteString(strings.Repeat(" --- |", cols))
+	b.WriteString("\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// MarkdownTableOf 将结构体切片渲染为 Markdown V2 表格。表头取自字段的 md 标签，未设置标签时使用字段名，
+// 标签为 - 的字段及未导出的字段将被忽略。切片元素可以是结构体或结构体指针，nil 指针渲染为空单元格。
+func MarkdownTableOf(items interface{}) (string, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("MarkdownTableOf requires a slice of structs, got %T", items)
+	}
+
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return "", fmt.Errorf("MarkdownTableOf requires a slice of structs, got %T", items)
+	}
+
+	var header []string
+	var fields []int
+	for i := 0; i < elem.NumField(); i++ {
+		f := elem.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Tag.Get("md")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	rows := [][]string{header}
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				rows = append(rows, make([]string, len(fields)))
+				continue
+			}
+			item = item.Elem()
+		}
+
+		row := make([]string, 0, len(fields))
+		for _, idx := range fields {
+			row = append(row, formatCell(item.Field(idx)))
+		}
+		rows = append(rows, row)
+	}
+	return MarkdownTable(rows), nil
+}
+
+func formatCell(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprint(v.Interface())
+}
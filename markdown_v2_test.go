@@ -0,0 +1,49 @@
+package wecombot_test
+
+import (
+	"testing"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestMarkdownTableOf(t *testing.T) {
+	type score struct {
+		Name    string  `md:"姓名"`
+		Math    int     `md:"数学"`
+		Comment *string `md:"评语"`
+		secret  string
+		Ignored []string `md:"-"`
+	}
+	comment := "优|良"
+
+	got, err := wecombot.MarkdownTableOf([]*score{{Name: "张三", Math: 97, Comment: &comment}, {Name: "李四", Math: 61}, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "| 姓名 | 数学 | 评语 |\n" +
+		"| --- | --- | --- |\n" +
+		"| 张三 | 97 | 优\\|良 |\n" +
+		"| 李四 | 61 |  |\n" +
+		"|  |  |  |\n"
+	if got != want {
+		t.Errorf("MarkdownTableOf() = %q, want %q", got, want)
+	}
+
+	want = "MarkdownTableOf requires a slice of structs, got []string"
+	if _, err = wecombot.MarkdownTableOf([]string{"a"}); err == nil || err.Error() != want {
+		t.Errorf("MarkdownTableOf() with non-struct elements error = %v, want %q", err, want)
+	}
+}
+
+func TestBot_MarkdownV2Fallback(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	table := wecombot.MarkdownTable([][]string{{"模块", "结果"}, {"api", "通过"}})
+	srv.FailWith(40008, "invalid message type")
+	if err := srv.Bot("test-key", wecombot.WithMarkdownV2Fallback()).SendMarkdownV2(table); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpectMarkdown(t, "**结果**: 通过")
+}
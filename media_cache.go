@@ -0,0 +1,255 @@
+package wecombot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// MediaLifetime media_id 的有效期，自上传时起3天内有效。
+	MediaLifetime = 3 * 24 * time.Hour
+	// DefaultMediaCacheMargin 缓存条目在 media_id 过期前提前失效的时长
+	DefaultMediaCacheMargin = time.Hour
+)
+
+// MediaCache media_id 缓存，用于在有效期内复用相同文件的上传结果。
+// 缓存键由机器人 key 、文件类型及文件内容（普通文件还包括文件名）的 sha256 摘要组成，同一个 key 下相同的文件只需上传一次。
+type MediaCache interface {
+	// Get 返回缓存的 media_id ，不存在或已过期时 ok 为 false 。
+	Get(key string) (mediaID string, ok bool)
+	// Set 缓存文件上传结果，有效期根据 media.CreatedAt 计算。
+	Set(key string, media *UploadedMedia) error
+	// Delete 删除缓存
+	Delete(key string) error
+}
+
+// WithMediaCache 设置 media_id 缓存。开启后 SendFile/SendVoice 会复用有效期内的 media_id ，
+// 若服务端返回 media_id 无效，则重新上传后再次发送。缓存的读写失败不影响消息发送。
+func WithMediaCache(c MediaCache) func(*Bot) {
+	return func(bot *Bot) {
+		bot.mediaCache = c
+	}
+}
+
+// mediaCacheKey 返回文件的缓存键。机器人 key 同样以摘要形式出现，避免在缓存文件中明文保存。
+// 普通文件在群聊中会展示文件名，因此文件名也计入摘要；语音消息不展示文件名。
+func mediaCacheKey(key string, tpe FileType, filename string, data []byte) string {
+	k := sha256.Sum256([]byte(key))
+	h := sha256.New()
+	if tpe == NormalFile {
+		h.Write([]byte(filename))
+		h.Write([]byte{0})
+	}
+	h.Write(data)
+	return hex.EncodeToString(k[:8]) + ":" + string(tpe) + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// sendMedia 上传文件（或复用缓存的 media_id ）后调用 send 发送消息
+func (bot *Bot) sendMedia(ctx context.Context, tpe FileType, f []byte, filename string, send func(mediaID string) error) error {
+	if bot.mediaCache == nil {
+		ret, err := bot.UploadMediaContext(ctx, tpe, f, filename)
+		if err != nil {
+			return err
+		}
+		return send(ret.MediaID)
+	}
+
+	key := mediaCacheKey(bot.key, tpe, filename, f)
+	if mediaID, ok := bot.mediaCache.Get(key); ok {
+		err := send(mediaID)
+		if !errors.Is(err, ErrInvalidMediaID) {
+			return err
+		}
+		_ = bot.mediaCache.Delete(key)
+	}
+
+	ret, err := bot.UploadMediaContext(ctx, tpe, f, filename)
+	if err != nil {
+		return err
+	}
+	_ = bot.mediaCache.Set(key, ret)
+	return send(ret.MediaID)
+}
+
+// CreatedTime 返回文件的上传时间。CreatedAt 为空或无法解析时返回错误。
+func (um *UploadedMedia) CreatedTime() (time.Time, error) {
+	sec, err := strconv.ParseInt(um.CreatedAt, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// mediaExpiresAt 返回缓存条目的失效时间。上传时间无法解析时以当前时间计算。
+func mediaExpiresAt(media *UploadedMedia, margin time.Duration) time.Time {
+	created, err := media.CreatedTime()
+	if err != nil {
+		created = time.Now()
+	}
+	return created.Add(MediaLifetime - margin)
+}
+
+type mediaCacheEntry struct {
+	MediaID   string    `json:"media_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MemoryMediaCache 基于内存的 media_id 缓存，可安全地并发使用。
+type MemoryMediaCache struct {
+	margin  time.Duration
+	mu      sync.Mutex
+	entries map[string]mediaCacheEntry
+}
+
+// WithMediaCacheMargin 设置缓存条目在 media_id 过期前提前失效的时长，默认为 DefaultMediaCacheMargin 。
+func WithMediaCacheMargin(d time.Duration) func(*MemoryMediaCache) {
+	return func(c *MemoryMediaCache) {
+		c.margin = d
+	}
+}
+
+// NewMemoryMediaCache 返回基于内存的 media_id 缓存
+func NewMemoryMediaCache(opts ...func(*MemoryMediaCache)) *MemoryMediaCache {
+	c := MemoryMediaCache{
+		margin:  DefaultMediaCacheMargin,
+		entries: make(map[string]mediaCacheEntry),
+	}
+	for _, setter := range opts {
+		setter(&c)
+	}
+	return &c
+}
+
+// Get 返回缓存的 media_id ，不存在或已过期时 ok 为 false 。
+func (c *MemoryMediaCache) Get(key string) (mediaID string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	one, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !time.Now().Before(one.ExpiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return one.MediaID, true
+}
+
+// Set 缓存文件上传结果，同时清理已过期的条目。
+func (c *MemoryMediaCache) Set(key string, media *UploadedMedia) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, media)
+	return nil
+}
+
+func (c *MemoryMediaCache) set(key string, media *UploadedMedia) {
+	c.evict()
+	if media.MediaID == "" {
+		return
+	}
+	if at := mediaExpiresAt(media, c.margin); time.Now().Before(at) {
+		c.entries[key] = mediaCacheEntry{MediaID: media.MediaID, ExpiresAt: at}
+	}
+}
+
+// Delete 删除缓存
+func (c *MemoryMediaCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+// Len 返回缓存条目数（包括尚未清理的过期条目）
+func (c *MemoryMediaCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *MemoryMediaCache) evict() {
+	now := time.Now()
+	for key, one := range c.entries {
+		if !now.Before(one.ExpiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// FileMediaCache 基于本地文件的 media_id 缓存，可在进程重启或多次运行（如定时任务）之间复用上传结果。
+// 缓存以 JSON 格式保存，每次写入时整体替换文件。可安全地并发使用，但不支持多个进程同时写入同一文件。
+type FileMediaCache struct {
+	*MemoryMediaCache
+	filename string
+}
+
+// OpenFileMediaCache 打开文件缓存，文件不存在时将在首次写入时创建，已过期的条目在加载时丢弃。
+func OpenFileMediaCache(filename string, opts ...func(*MemoryMediaCache)) (*FileMediaCache, error) {
+	c := FileMediaCache{
+		MemoryMediaCache: NewMemoryMediaCache(opts...),
+		filename:         filename,
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &c.entries); err != nil {
+			return nil, err
+		}
+	}
+	c.evict()
+	return &c, nil
+}
+
+// Set 缓存文件上传结果并写入文件
+func (c *FileMediaCache) Set(key string, media *UploadedMedia) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, media)
+	return c.save()
+}
+
+// Delete 删除缓存并写入文件
+func (c *FileMediaCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		return nil
+	}
+	delete(c.entries, key)
+	return c.save()
+}
+
+func (c *FileMediaCache) save() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.filename), filepath.Base(c.filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.filename)
+}
@@ -0,0 +1,99 @@
+package wecombot
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func uploadedAt(mediaID string, t time.Time) *UploadedMedia {
+	return &UploadedMedia{MediaID: mediaID, CreatedAt: strconv.FormatInt(t.Unix(), 10)}
+}
+
+func TestMemoryMediaCache(t *testing.T) {
+	c := NewMemoryMediaCache()
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		media  *UploadedMedia
+		wantOK bool
+	}{
+		{name: "fresh", media: uploadedAt("m1", now), wantOK: true},
+		{name: "within margin", media: uploadedAt("m2", now.Add(-MediaLifetime+30*time.Minute)), wantOK: false},
+		{name: "expired", media: uploadedAt("m3", now.Add(-MediaLifetime-time.Minute)), wantOK: false},
+		{name: "invalid created_at", media: &UploadedMedia{MediaID: "m4", CreatedAt: "n/a"}, wantOK: true},
+		{name: "empty media_id", media: uploadedAt("", now), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.Set(tt.name, tt.media); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := c.Get(tt.name)
+			if ok != tt.wantOK || (ok && got != tt.media.MediaID) {
+				t.Errorf("Get() = %q, %v, want %q, %v", got, ok, tt.media.MediaID, tt.wantOK)
+			}
+		})
+	}
+
+	if err := c.Delete("fresh"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("fresh"); ok {
+		t.Error("Get() after Delete() ok = true")
+	}
+}
+
+func TestFileMediaCache(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "media.json")
+
+	c, err := OpenFileMediaCache(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Set("a", uploadedAt("m1", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Set("b", uploadedAt("m2", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = OpenFileMediaCache(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Get("a"); !ok || got != "m1" {
+		t.Errorf("Get(a) = %q, %v, want m1, true", got, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) ok = true, want false")
+	}
+}
+
+func TestMediaCacheKey(t *testing.T) {
+	data := []byte("hello world")
+	k := mediaCacheKey("key1", NormalFile, "a.pdf", data)
+	if k != mediaCacheKey("key1", NormalFile, "a.pdf", []byte("hello world")) {
+		t.Error("same input produces different keys")
+	}
+	for _, other := range []string{
+		mediaCacheKey("key2", NormalFile, "a.pdf", data),
+		mediaCacheKey("key1", VoiceFile, "a.pdf", data),
+		mediaCacheKey("key1", NormalFile, "b.pdf", data),
+		mediaCacheKey("key1", NormalFile, "a.pdf", []byte("hello world!")),
+	} {
+		if other == k {
+			t.Errorf("mediaCacheKey() collision: %s", k)
+		}
+	}
+
+	// 语音消息不展示文件名，相同内容可复用
+	if mediaCacheKey("key1", VoiceFile, "a.amr", data) != mediaCacheKey("key1", VoiceFile, "b.amr", data) {
+		t.Error("voice cache key depends on filename")
+	}
+}
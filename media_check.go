@@ -0,0 +1,195 @@
+package wecombot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 上传文件的限制
+const (
+	// MinMediaBytes 上传文件的最小字节数
+	MinMediaBytes = 5
+	// MaxFileBytes 普通文件的最大字节数
+	MaxFileBytes = 20 << 20
+	// MaxVoiceBytes 语音文件的最大字节数
+	MaxVoiceBytes = 2 << 20
+	// MaxVoiceDuration 语音文件的最大播放时长
+	MaxVoiceDuration = 60 * time.Second
+)
+
+// MediaSizeError 上传文件的大小超出限制
+type MediaSizeError struct {
+	// Type 文件类型
+	Type FileType
+	// Size 文件大小
+	Size int64
+	// Min 允许的最小字节数
+	Min int64
+	// Max 允许的最大字节数
+	Max int64
+}
+
+// Error 返回文本形式的错误描述
+func (e *MediaSizeError) Error() string {
+	return fmt.Sprintf("%s size must be between %d and %d bytes, got %d", e.Type, e.Min, e.Max, e.Size)
+}
+
+// VoiceFormatError 语音文件不是 AMR 格式
+type VoiceFormatError struct {
+	// Header 文件开头的若干字节
+	Header []byte
+}
+
+// Error 返回文本形式的错误描述
+func (e *VoiceFormatError) Error() string {
+	return fmt.Sprintf("voice must be in AMR format, got header %q", e.Header)
+}
+
+// VoiceDurationError 语音文件的播放时长超出限制
+type VoiceDurationError struct {
+	// Duration 根据 AMR 帧数估算的播放时长
+	Duration time.Duration
+	// Max 允许的最大播放时长
+	Max time.Duration
+}
+
+// Error 返回文本形式的错误描述
+func (e *VoiceDurationError) Error() string {
+	return fmt.Sprintf("voice duration must be at most %s, got about %s", e.Max, e.Duration)
+}
+
+// checkMediaSize 校验上传文件的大小是否符合限制
+func checkMediaSize(tpe FileType, size int64) error {
+	max := int64(MaxFileBytes)
+	if tpe == VoiceFile {
+		max = MaxVoiceBytes
+	}
+	if size < MinMediaBytes || size > max {
+		return &MediaSizeError{Type: tpe, Size: size, Min: MinMediaBytes, Max: max}
+	}
+	return nil
+}
+
+// checkMedia 在上传前校验文件：大小须符合限制，语音文件还须为 AMR 格式且播放时长不超过 MaxVoiceDuration 。
+func checkMedia(tpe FileType, data []byte) error {
+	if err := checkMediaSize(tpe, int64(len(data))); err != nil {
+		return err
+	}
+	if tpe != VoiceFile {
+		return nil
+	}
+
+	d, ok := AMRDuration(data)
+	if !ok {
+		header := data
+		if len(header) > len(amrWBMagic) {
+			header = header[:len(amrWBMagic)]
+		}
+		return &VoiceFormatError{Header: header}
+	}
+	if d > MaxVoiceDuration {
+		return &VoiceDurationError{Duration: d, Max: MaxVoiceDuration}
+	}
+	return nil
+}
+
+var (
+	amrMagic   = []byte("#!AMR\n")
+	amrWBMagic = []byte("#!AMR-WB\n")
+
+	// 各帧类型（FT）对应的帧长度（含1字节帧头）
+	amrFrameSizes   = [16]int{13, 14, 16, 18, 20, 21, 27, 32, 6, 1, 1, 1, 1, 1, 1, 1}
+	amrWBFrameSizes = [16]int{18, 24, 33, 37, 41, 47, 51, 59, 61, 6, 1, 1, 1, 1, 1, 1}
+)
+
+// amrFrameDuration AMR 每一帧的时长
+const amrFrameDuration = 20 * time.Millisecond
+
+// AMRDuration 根据帧数估算 AMR（AMR-NB 或 AMR-WB）文件的播放时长，data 不是 AMR 格式时 ok 为 false 。
+// 末尾不完整的帧不计入时长。
+func AMRDuration(data []byte) (d time.Duration, ok bool) {
+	var sizes *[16]int
+	switch {
+	case bytes.HasPrefix(data, amrWBMagic):
+		sizes, data = &amrWBFrameSizes, data[len(amrWBMagic):]
+	case bytes.HasPrefix(data, amrMagic):
+		sizes, data = &amrFrameSizes, data[len(amrMagic):]
+	default:
+		return 0, false
+	}
+
+	var frames int
+	for len(data) > 0 {
+		n := sizes[(data[0]>>3)&0x0f]
+		if n > len(data) {
+			break
+		}
+		frames++
+		data = data[n:]
+	}
+	return time.Duration(frames) * amrFrameDuration, true
+}
+
+// mediaContentDisposition 返回上传文件的 Content-Disposition 。
+// 文件名按照 RFC 7578 放入 filename 参数（去除控制字符，引号及反斜杠替换为下划线），
+// 含非 ASCII 字符时另按照 RFC 5987 附加 filename* 参数。
+func mediaContentDisposition(filename string, size int64) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filename)
+	if name == "" {
+		name = "media"
+	}
+
+	var b strings.Builder
+	b.WriteString(`form-data; name="media"; filename="`)
+	b.WriteString(strings.NewReplacer(`"`, "_", `\`, "_").Replace(name))
+	b.WriteByte('"')
+	if !isASCII(name) {
+		b.WriteString("; filename*=UTF-8''")
+		b.WriteString(encodeRFC5987(name))
+	}
+	fmt.Fprintf(&b, "; filelength=%d", size)
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeRFC5987 按照 RFC 5987 对参数值进行百分号编码，仅保留 attr-char 字符。
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,106 @@
+package wecombot
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// amrFile 返回包含 n 个 12.2kbit/s 帧的 AMR-NB 文件
+func amrFile(n int) []byte {
+	frame := make([]byte, 32)
+	frame[0] = 7 << 3
+	return append([]byte("#!AMR\n"), bytes.Repeat(frame, n)...)
+}
+
+func TestAMRDuration(t *testing.T) {
+	wb := append([]byte("#!AMR-WB\n"), bytes.Repeat(append([]byte{2 << 3}, make([]byte, 32)...), 50)...)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "amr-nb", data: amrFile(100), want: 2 * time.Second, wantOK: true},
+		{name: "amr-wb", data: wb, want: time.Second, wantOK: true},
+		{name: "truncated frame", data: amrFile(10)[:6+32*9+5], want: 180 * time.Millisecond, wantOK: true},
+		{name: "header only", data: []byte("#!AMR\n"), want: 0, wantOK: true},
+		{name: "mp3", data: []byte("ID3\x03\x00\x00\x00\x00\x00"), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := AMRDuration(tt.data)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("AMRDuration() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckMedia(t *testing.T) {
+	var (
+		mse *MediaSizeError
+		vfe *VoiceFormatError
+		vde *VoiceDurationError
+	)
+	tests := []struct {
+		name   string
+		tpe    FileType
+		data   []byte
+		target interface{}
+	}{
+		{name: "file", tpe: NormalFile, data: []byte("hello")},
+		{name: "file too small", tpe: NormalFile, data: []byte("hi"), target: &mse},
+		{name: "file too large", tpe: NormalFile, data: make([]byte, MaxFileBytes+1), target: &mse},
+		{name: "voice", tpe: VoiceFile, data: amrFile(3000)},
+		{name: "voice too large", tpe: VoiceFile, data: make([]byte, MaxVoiceBytes+1), target: &mse},
+		{name: "voice not amr", tpe: VoiceFile, data: []byte("RIFF\x00\x00\x00\x00WAVE"), target: &vfe},
+		{name: "voice too long", tpe: VoiceFile, data: amrFile(3001), target: &vde},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMedia(tt.tpe, tt.data)
+			if tt.target == nil {
+				if err != nil {
+					t.Errorf("checkMedia() error = %v", err)
+				}
+				return
+			}
+			if !errors.As(err, tt.target) || !IsPermanent(err) {
+				t.Errorf("checkMedia() error = %v, want %T", err, tt.target)
+			}
+		})
+	}
+}
+
+func TestMediaContentDisposition(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{filename: "report.pdf", want: "report.pdf"},
+		{filename: "学生成绩单.xlsx", want: "学生成绩单.xlsx"},
+		{filename: "a\"b\\c.txt", want: "a_b_c.txt"},
+		{filename: "evil\r\nContent-Type: text/html.txt", want: "evilContent-Type: text/html.txt"},
+		{filename: "", want: "media"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			header := mediaContentDisposition(tt.filename, 42)
+			disposition, params, err := mime.ParseMediaType(header)
+			if err != nil {
+				t.Fatalf("ParseMediaType(%q) error = %v", header, err)
+			}
+			if disposition != "form-data" || params["name"] != "media" || params["filelength"] != strconv.Itoa(42) {
+				t.Errorf("header = %q", header)
+			}
+			if params["filename"] != tt.want {
+				t.Errorf("filename = %q, want %q", params["filename"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package wecombot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Message 消息。所有消息类型（ *TextMessage 、 *MarkdownMessage 、 *MarkdownV2Message 、 *ImageMessage 、 *NewsMessage 、 *FileMessage 、
+// *VoiceMessage 、 *TextNoticeTemplateCardMessage 、 *NewsNoticeTemplateCardMessage ）均实现了该接口。
+type Message interface {
+	// Type 返回消息类型
+	Type() MsgType
+	// Validate 校验消息内容
+	Validate() error
+	// MarshalJSON 返回消息的 JSON 编码，其中的 msgtype 等类型字段会被自动填充。
+	MarshalJSON() ([]byte, error)
+}
+
+// Send 发送消息
+func (bot *Bot) Send(ctx context.Context, msg Message) error {
+	return bot.send(ctx, msg)
+}
+
+// ParseMessage 根据 msgtype 及 card_type 将 webhook 消息的 JSON 数据解码为对应的消息类型
+func ParseMessage(data []byte) (Message, error) {
+	var head struct {
+		MsgType      MsgType `json:"msgtype"`
+		TemplateCard struct {
+			CardType CardType `json:"card_type"`
+		} `json:"template_card"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	var msg Message
+	switch head.MsgType {
+	case TextMsgType:
+		msg = new(TextMessage)
+	case MarkdownMsgType:
+		msg = new(MarkdownMessage)
+	case MarkdownV2MsgType:
+		msg = new(MarkdownV2Message)
+	case ImageMsgType:
+		msg = new(ImageMessage)
+	case NewsMsgType:
+		msg = new(NewsMessage)
+	case FileMsgType:
+		msg = new(FileMessage)
+	case VoiceMsgType:
+		msg = new(VoiceMessage)
+	case TemplateCardMsgType:
+		switch head.TemplateCard.CardType {
+		case TextNoticeCardType:
+			msg = new(TextNoticeTemplateCardMessage)
+		case NewsNoticeCardType:
+			msg = new(NewsNoticeTemplateCardMessage)
+		default:
+			return nil, fmt.Errorf("unsupported card_type: %q", head.TemplateCard.CardType)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported msgtype: %q", head.MsgType)
+	}
+
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Type 返回消息类型
+func (msg *TextMessage) Type() MsgType {
+	return TextMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *TextMessage) MarshalJSON() ([]byte, error) {
+	type alias TextMessage
+	one := alias(*msg)
+	one.MsgType = TextMsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *MarkdownMessage) Type() MsgType {
+	return MarkdownMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *MarkdownMessage) MarshalJSON() ([]byte, error) {
+	type alias MarkdownMessage
+	one := alias(*msg)
+	one.MsgType = MarkdownMsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *MarkdownV2Message) Type() MsgType {
+	return MarkdownV2MsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *MarkdownV2Message) MarshalJSON() ([]byte, error) {
+	type alias MarkdownV2Message
+	one := alias(*msg)
+	one.MsgType = MarkdownV2MsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *ImageMessage) Type() MsgType {
+	return ImageMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *ImageMessage) MarshalJSON() ([]byte, error) {
+	type alias ImageMessage
+	one := alias(*msg)
+	one.MsgType = ImageMsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *NewsMessage) Type() MsgType {
+	return NewsMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *NewsMessage) MarshalJSON() ([]byte, error) {
+	type alias NewsMessage
+	one := alias(*msg)
+	one.MsgType = NewsMsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *FileMessage) Type() MsgType {
+	return FileMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *FileMessage) MarshalJSON() ([]byte, error) {
+	type alias FileMessage
+	one := alias(*msg)
+	one.MsgType = FileMsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *VoiceMessage) Type() MsgType {
+	return VoiceMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *VoiceMessage) MarshalJSON() ([]byte, error) {
+	type alias VoiceMessage
+	one := alias(*msg)
+	one.MsgType = VoiceMsgType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *TextNoticeTemplateCardMessage) Type() MsgType {
+	return TemplateCardMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *TextNoticeTemplateCardMessage) MarshalJSON() ([]byte, error) {
+	type alias TextNoticeTemplateCardMessage
+	one := alias(*msg)
+	one.MsgType = TemplateCardMsgType
+	one.TemplateCard.CardType = TextNoticeCardType
+	return json.Marshal(&one)
+}
+
+// Type 返回消息类型
+func (msg *NewsNoticeTemplateCardMessage) Type() MsgType {
+	return TemplateCardMsgType
+}
+
+// MarshalJSON 返回消息的 JSON 编码
+func (msg *NewsNoticeTemplateCardMessage) MarshalJSON() ([]byte, error) {
+	type alias NewsNoticeTemplateCardMessage
+	one := alias(*msg)
+	one.MsgType = TemplateCardMsgType
+	one.TemplateCard.CardType = NewsNoticeCardType
+	return json.Marshal(&one)
+}
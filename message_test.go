@@ -0,0 +1,46 @@
+package wecombot
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	var card TextNoticeTemplateCardMessage
+	title := "欢迎使用企业微信"
+	card.TemplateCard.MainTitle.Title = &title
+
+	tests := []struct {
+		name     string
+		msg      Message
+		wantType MsgType
+	}{
+		{name: "文本消息", msg: &TextMessage{}, wantType: TextMsgType},
+		{name: "Markdown消息", msg: &MarkdownMessage{}, wantType: MarkdownMsgType},
+		{name: "文件消息", msg: &FileMessage{}, wantType: FileMsgType},
+		{name: "文本通知模板卡片消息", msg: &card, wantType: TemplateCardMsgType},
+		{name: "图文展示模板卡片消息", msg: &NewsNoticeTemplateCardMessage{}, wantType: TemplateCardMsgType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseMessage(data)
+			if err != nil {
+				t.Fatalf("ParseMessage() error = %v", err)
+			}
+			if got.Type() != tt.wantType {
+				t.Errorf("ParseMessage().Type() = %v, want %v", got.Type(), tt.wantType)
+			}
+			if gotData, _ := json.Marshal(got); string(gotData) != string(data) {
+				t.Errorf("ParseMessage() round trip = %s, want %s", gotData, data)
+			}
+		})
+	}
+
+	if _, err := ParseMessage([]byte(`{"msgtype":"unknown"}`)); err == nil {
+		t.Error("ParseMessage() with unknown msgtype should return an error")
+	}
+}
@@ -0,0 +1,63 @@
+package wecombot
+
+import (
+	"context"
+	"io"
+)
+
+// Request 一次接口调用的请求
+type Request struct {
+	// Key 机器人 webhook key
+	Key string
+	// Message 待发送的消息，文件上传时为 nil 。
+	Message Message
+	// Upload 待上传的文件，发送消息时为 nil 。
+	Upload *MediaUpload
+}
+
+// MediaUpload 待上传的文件
+type MediaUpload struct {
+	// Type 文件类型
+	Type FileType
+	// Filename 文件名
+	Filename string
+	// Data 文件内容，Reader 不为 nil 时忽略。
+	Data []byte
+	// Reader 以流的方式上传时的文件内容，此时 Size 为文件的字节数。
+	Reader io.Reader
+	// Size 文件的字节数，仅 Reader 不为 nil 时有效。
+	Size int64
+}
+
+// Response 接口响应
+type Response struct {
+	// ErrCode 错误码
+	ErrCode int `json:"errcode"`
+	// ErrMsg 错误信息
+	ErrMsg string `json:"errmsg"`
+	// MediaID 媒体文件id，仅文件上传时有效。
+	MediaID string `json:"media_id,omitempty"`
+	// CreatedAt 媒体文件上传时间戳，仅文件上传时有效。
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+func (res *Response) toError() error {
+	if res.ErrCode == 0 {
+		return nil
+	}
+	return NewResError(res.ErrCode, res.ErrMsg)
+}
+
+// SendFunc 执行一次接口调用（发送消息或上传文件）。服务端返回非0错误码时，同时返回响应及对应的 *ResError 。
+type SendFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware 接口调用中间件。中间件可在调用 next 之前检查或修改请求（此时消息尚未编码），
+// 也可在 next 返回后检查响应及错误；不调用 next 则请求不会发出。
+type Middleware func(next SendFunc) SendFunc
+
+// WithMiddleware 添加接口调用中间件，先添加的中间件位于调用链的外层。中间件同时作用于消息发送与文件上传。
+func WithMiddleware(mw ...Middleware) func(*Bot) {
+	return func(bot *Bot) {
+		bot.middlewares = append(bot.middlewares, mw...)
+	}
+}
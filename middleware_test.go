@@ -0,0 +1,54 @@
+package wecombot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestBot_Middleware(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	var calls int
+	prefix := func(next wecombot.SendFunc) wecombot.SendFunc {
+		return func(ctx context.Context, req *wecombot.Request) (*wecombot.Response, error) {
+			calls++
+			if msg, ok := req.Message.(*wecombot.TextMessage); ok {
+				msg.Text.Content = "[staging] " + msg.Text.Content
+			}
+			return next(ctx, req)
+		}
+	}
+
+	bot := srv.Bot("test-key", wecombot.WithMiddleware(prefix))
+	if err := bot.SendFile([]byte("hello world"), "hello.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.SendText("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.ExpectText(t, "[staging] hello")
+	if calls != 3 {
+		t.Errorf("middleware called %d times, want 3", calls)
+	}
+
+	// 中间件拦截文件上传且未设置响应时，不会发送空的 media_id
+	drop := func(next wecombot.SendFunc) wecombot.SendFunc {
+		return func(ctx context.Context, req *wecombot.Request) (*wecombot.Response, error) {
+			if req.Upload != nil {
+				return nil, nil
+			}
+			return next(ctx, req)
+		}
+	}
+	srv.Reset()
+	if err := srv.Bot("test-key", wecombot.WithMiddleware(drop)).SendFile([]byte("hello world"), "hello.txt"); !errors.Is(err, wecombot.ErrEmptyMediaID) {
+		t.Errorf("SendFile() error = %v, want %v", err, wecombot.ErrEmptyMediaID)
+	}
+	srv.ExpectMessageCount(t, 0)
+}
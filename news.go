@@ -1,5 +1,7 @@
 package wecombot
 
+import "context"
+
 // NewsMessage 图文类型消息。详见 https://developer.work.weixin.qq.com/document/path/91770#%E5%9B%BE%E6%96%87%E7%B1%BB%E5%9E%8B
 type NewsMessage struct {
 	// MsgType 必填。消息类型，此时固定为 news 。
@@ -24,13 +26,23 @@ type Article struct {
 
 // SendNewsMessage 发送图文消息
 func (bot *Bot) SendNewsMessage(msg *NewsMessage) (err error) {
+	return bot.SendNewsMessageContext(context.Background(), msg)
+}
+
+// SendNewsMessageContext 发送图文消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendNewsMessageContext(ctx context.Context, msg *NewsMessage) (err error) {
 	msg.MsgType = NewsMsgType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendNews 发送图文消息
 func (bot *Bot) SendNews(articles ...*Article) (err error) {
+	return bot.SendNewsContext(context.Background(), articles...)
+}
+
+// SendNewsContext 发送图文消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendNewsContext(ctx context.Context, articles ...*Article) (err error) {
 	var msg NewsMessage
 	msg.News.Articles = articles
-	return bot.SendNewsMessage(&msg)
+	return bot.SendNewsMessageContext(ctx, &msg)
 }
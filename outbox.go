@@ -0,0 +1,444 @@
+package wecombot
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	outboxFilename = "outbox.log"
+
+	// DefaultOutboxRetention 已投递条目的幂等键的默认保留时长
+	DefaultOutboxRetention = 24 * time.Hour
+	// DefaultOutboxCompactInterval 默认的定期压缩间隔
+	DefaultOutboxCompactInterval = 10 * time.Minute
+	// DefaultOutboxCompactThreshold 自上次压缩以来，已投递条目数达到该值时自动压缩。
+	DefaultOutboxCompactThreshold = 1000
+)
+
+const (
+	outboxOpPut  = "put"
+	outboxOpDone = "done"
+)
+
+// OutboxEntry 发件箱中待投递的消息
+type OutboxEntry struct {
+	// ID 幂等键
+	ID string
+	// Key 机器人 webhook key
+	Key string
+	// Message 消息的 JSON 编码
+	Message json.RawMessage
+	// CreatedAt 写入时间
+	CreatedAt time.Time
+}
+
+type outboxRecord struct {
+	Op      string          `json:"op"`
+	ID      string          `json:"id"`
+	Key     string          `json:"key,omitempty"`
+	Message json.RawMessage `json:"msg,omitempty"`
+	Time    time.Time       `json:"ts"`
+}
+
+// Outbox 持久化发件箱。消息在投递前先追加写入目录中的日志文件，投递成功后标记为已完成，
+// 进程重启后可通过 Bot.Replay 重新投递尚未完成的消息，从而实现至少一次（at-least-once）投递。
+type Outbox struct {
+	dir              string
+	retention        time.Duration
+	compactInterval  time.Duration
+	compactThreshold int
+
+	mu               sync.Mutex
+	f                *os.File
+	pending          map[string]*OutboxEntry
+	order            []string
+	done             map[string]time.Time
+	doneSinceCompact int
+	stop             chan struct{}
+	closed           bool
+	// compactErr 自动压缩失败的错误，由下一次 Compact 或 Close 返回。
+	compactErr error
+}
+
+// WithOutboxRetention 设置已投递条目的幂等键的保留时长，保留期内重复写入相同幂等键的消息将被忽略。
+func WithOutboxRetention(d time.Duration) func(*Outbox) {
+	return func(ob *Outbox) {
+		ob.retention = d
+	}
+}
+
+// WithOutboxCompaction 设置定期压缩的间隔以及触发压缩的已投递条目数，interval 为0时不定期压缩。
+func WithOutboxCompaction(interval time.Duration, threshold int) func(*Outbox) {
+	return func(ob *Outbox) {
+		ob.compactInterval = interval
+		ob.compactThreshold = threshold
+	}
+}
+
+// OpenOutbox 打开（或创建）dir 目录下的发件箱，并加载其中尚未投递完成的消息。
+func OpenOutbox(dir string, opts ...func(*Outbox)) (*Outbox, error) {
+	ob := Outbox{
+		dir:              dir,
+		retention:        DefaultOutboxRetention,
+		compactInterval:  DefaultOutboxCompactInterval,
+		compactThreshold: DefaultOutboxCompactThreshold,
+		pending:          make(map[string]*OutboxEntry),
+		done:             make(map[string]time.Time),
+		stop:             make(chan struct{}),
+	}
+	for _, setter := range opts {
+		setter(&ob)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := ob.load(); err != nil {
+		return nil, err
+	}
+	if err := ob.Compact(); err != nil {
+		return nil, err
+	}
+
+	if ob.compactInterval > 0 {
+		go ob.compactLoop()
+	}
+	return &ob, nil
+}
+
+func (ob *Outbox) filename() string {
+	return filepath.Join(ob.dir, outboxFilename)
+}
+
+// load 回放日志文件，重建待投递及已投递条目。无法解析的行（如进程崩溃时写入不完整的末行）将被忽略。
+func (ob *Outbox) load() error {
+	f, err := os.Open(ob.filename())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	for scanner.Scan() {
+		var rec outboxRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+			continue
+		}
+		ob.apply(&rec)
+	}
+	return scanner.Err()
+}
+
+func (ob *Outbox) apply(rec *outboxRecord) {
+	switch rec.Op {
+	case outboxOpPut:
+		if _, ok := ob.done[rec.ID]; ok {
+			return
+		}
+		if _, ok := ob.pending[rec.ID]; !ok {
+			ob.order = append(ob.order, rec.ID)
+		}
+		ob.pending[rec.ID] = &OutboxEntry{
+			ID:        rec.ID,
+			Key:       rec.Key,
+			Message:   rec.Message,
+			CreatedAt: rec.Time,
+		}
+	case outboxOpDone:
+		delete(ob.pending, rec.ID)
+		ob.done[rec.ID] = rec.Time
+	}
+}
+
+func (ob *Outbox) write(rec *outboxRecord) error {
+	if ob.closed {
+		return os.ErrClosed
+	}
+	if ob.f == nil {
+		f, err := os.OpenFile(ob.filename(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+		ob.f = f
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err = ob.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return ob.f.Sync()
+}
+
+// Put 在投递前写入消息。id 为幂等键，若该键对应的消息已写入或已投递，则忽略本次写入并返回 false 。
+func (ob *Outbox) Put(key, id string, msg Message) (bool, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if _, ok := ob.pending[id]; ok {
+		return false, nil
+	}
+	if _, ok := ob.done[id]; ok {
+		return false, nil
+	}
+
+	rec := outboxRecord{Op: outboxOpPut, ID: id, Key: key, Message: data, Time: time.Now()}
+	if err = ob.write(&rec); err != nil {
+		return false, err
+	}
+	ob.apply(&rec)
+	return true, nil
+}
+
+// Done 将消息标记为已投递
+func (ob *Outbox) Done(id string) error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	rec := outboxRecord{Op: outboxOpDone, ID: id, Time: time.Now()}
+	if err := ob.write(&rec); err != nil {
+		return err
+	}
+	ob.apply(&rec)
+
+	ob.doneSinceCompact++
+	if ob.compactThreshold > 0 && ob.doneSinceCompact >= ob.compactThreshold {
+		// 完成记录已落盘，压缩失败不影响投递结果。
+		ob.autoCompact()
+	}
+	return nil
+}
+
+// isPending 返回幂等键对应的消息是否尚未投递完成
+func (ob *Outbox) isPending(id string) bool {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	_, ok := ob.pending[id]
+	return ok
+}
+
+// Pending 按写入顺序返回 key 对应的尚未投递完成的消息
+func (ob *Outbox) Pending(key string) []*OutboxEntry {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	items := make([]*OutboxEntry, 0, len(ob.pending))
+	for _, id := range ob.order {
+		if one, ok := ob.pending[id]; ok && one.Key == key {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// Compact 压缩日志文件，仅保留尚未投递完成的消息以及保留期内已投递消息的幂等键。
+// 此前的自动压缩失败时，其错误会一并返回。
+func (ob *Outbox) Compact() error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	err := errors.Join(ob.compactErr, ob.compact())
+	ob.compactErr = nil
+	return err
+}
+
+// autoCompact 尽力压缩日志文件，失败时保留错误，由下一次 Compact 或 Close 返回。
+func (ob *Outbox) autoCompact() {
+	if err := ob.compact(); err != nil {
+		ob.compactErr = err
+	}
+}
+
+func (ob *Outbox) compact() error {
+	if ob.closed {
+		return os.ErrClosed
+	}
+	tmp, err := os.CreateTemp(ob.dir, outboxFilename+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	order := make([]string, 0, len(ob.pending))
+	for _, id := range ob.order {
+		one, ok := ob.pending[id]
+		if !ok {
+			continue
+		}
+		order = append(order, id)
+		if err = enc.Encode(&outboxRecord{Op: outboxOpPut, ID: one.ID, Key: one.Key, Message: one.Message, Time: one.CreatedAt}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(-ob.retention)
+	for id, at := range ob.done {
+		if at.Before(deadline) {
+			delete(ob.done, id)
+			continue
+		}
+		if err = enc.Encode(&outboxRecord{Op: outboxOpDone, ID: id, Time: at}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if ob.f != nil {
+		ob.f.Close()
+		ob.f = nil
+	}
+	if err = os.Rename(tmp.Name(), ob.filename()); err != nil {
+		return err
+	}
+	if err = syncDir(ob.dir); err != nil {
+		return err
+	}
+
+	ob.order = order
+	ob.doneSinceCompact = 0
+	return nil
+}
+
+// syncDir 将目录项（如重命名）同步至磁盘
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (ob *Outbox) compactLoop() {
+	ticker := time.NewTicker(ob.compactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ob.stop:
+			return
+		case <-ticker.C:
+			ob.mu.Lock()
+			if !ob.closed {
+				ob.autoCompact()
+			}
+			ob.mu.Unlock()
+		}
+	}
+}
+
+// Close 关闭发件箱。此前的自动压缩失败时，其错误会一并返回。
+func (ob *Outbox) Close() error {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if ob.closed {
+		return nil
+	}
+	ob.closed = true
+	close(ob.stop)
+
+	err := ob.compactErr
+	ob.compactErr = nil
+	if ob.f != nil {
+		err = errors.Join(err, ob.f.Close())
+		ob.f = nil
+	}
+	return err
+}
+
+// newIdempotencyKey 返回随机生成的幂等键
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithOutbox 设置持久化发件箱。设置后 SendDurable 及 SendAsync 发送的消息会在投递前写入发件箱。
+func WithOutbox(ob *Outbox) func(*Bot) {
+	return func(bot *Bot) {
+		bot.outbox = ob
+	}
+}
+
+// SendDurable 先将消息写入发件箱再投递，投递成功后标记为已完成；投递失败的消息保留在发件箱中，可通过 Replay 重新投递。
+// idempotencyKey 为幂等键，为空时自动生成。相同幂等键的消息已投递完成时直接返回 nil ；尚未投递完成（如上次投递失败）时重新投递。
+// 未设置发件箱时等同于 Send 。
+func (bot *Bot) SendDurable(ctx context.Context, msg Message, idempotencyKey string) error {
+	if bot.outbox == nil {
+		return bot.send(ctx, msg)
+	}
+	if idempotencyKey == "" {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	ok, err := bot.outbox.Put(bot.key, idempotencyKey, msg)
+	if err != nil {
+		return err
+	}
+	if !ok && !bot.outbox.isPending(idempotencyKey) {
+		return nil // 已投递完成
+	}
+	if err = bot.send(ctx, msg); err != nil {
+		return err
+	}
+	return bot.outbox.Done(idempotencyKey)
+}
+
+// Replay 按写入顺序重新投递发件箱中尚未投递完成的消息，通常在进程启动时调用。
+// 遇到投递失败时停止并返回已成功投递的消息数量及错误，以保证消息顺序。
+func (bot *Bot) Replay(ctx context.Context) (n int, err error) {
+	if bot.outbox == nil {
+		return 0, nil
+	}
+
+	for _, one := range bot.outbox.Pending(bot.key) {
+		msg, err := ParseMessage(one.Message)
+		if err != nil {
+			return n, err
+		}
+		if err = bot.send(ctx, msg); err != nil {
+			return n, err
+		}
+		if err = bot.outbox.Done(one.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
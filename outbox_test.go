@@ -0,0 +1,123 @@
+package wecombot_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestBot_Replay(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ob, err := wecombot.OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg wecombot.TextMessage
+	msg.Text.Content = "hello"
+
+	srv.FailHTTP(http.StatusServiceUnavailable)
+	if err = srv.Bot("test-key", wecombot.WithOutbox(ob)).SendDurable(context.Background(), &msg, "msg-1"); err == nil {
+		t.Fatal("SendDurable() should fail")
+	}
+	ob.Close()
+
+	// 模拟进程重启
+	if ob, err = wecombot.OpenOutbox(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer ob.Close()
+
+	bot := srv.Bot("test-key", wecombot.WithOutbox(ob))
+	if n, err := bot.Replay(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Replay() = %d, %v, want 1, nil", n, err)
+	}
+	if err = bot.SendDurable(context.Background(), &msg, "msg-1"); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpectText(t, "hello")
+}
+
+func TestBot_SendDurableRetry(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ob, err := wecombot.OpenOutbox(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := srv.Bot("test-key", wecombot.WithOutbox(ob))
+
+	var msg wecombot.TextMessage
+	msg.Text.Content = "hello"
+
+	srv.FailHTTP(http.StatusServiceUnavailable)
+	if err = bot.SendDurable(context.Background(), &msg, "msg-1"); err == nil {
+		t.Fatal("SendDurable() should fail")
+	}
+	// 调用方重试时重新投递尚未完成的消息
+	if err = bot.SendDurable(context.Background(), &msg, "msg-1"); err != nil {
+		t.Fatal(err)
+	}
+	// 已投递完成的消息不再重复投递
+	if err = bot.SendDurable(context.Background(), &msg, "msg-1"); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpectMessageCount(t, 1)
+
+	fi, err := os.Stat(filepath.Join(dir, "outbox.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("outbox.log mode = %o, want 600", perm)
+	}
+
+	if err = ob.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = ob.Compact(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Compact() after Close() error = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestOutbox_DoneCompactionFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	ob, err := wecombot.OpenOutbox(dir, wecombot.WithOutboxCompaction(0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o700 {
+		t.Errorf("outbox dir mode = %o, want 700", perm)
+	}
+
+	var msg wecombot.TextMessage
+	msg.Text.Content = "hello"
+	if _, err = ob.Put("test-key", "msg-1", &msg); err != nil {
+		t.Fatal(err)
+	}
+	// 删除目录后完成记录仍可写入已打开的日志文件，但压缩无法创建临时文件。
+	if err = os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err = ob.Done("msg-1"); err != nil {
+		t.Errorf("Done() error = %v, want nil when only compaction fails", err)
+	}
+	if err = ob.Close(); err == nil {
+		t.Error("Close() error = nil, want the compaction error")
+	}
+}
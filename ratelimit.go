@@ -0,0 +1,145 @@
+package wecombot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit 每个机器人 webhook key 在 DefaultRateLimitPeriod 内允许发送的消息数
+	DefaultRateLimit = 20
+	// DefaultRateLimitPeriod 默认限流周期
+	DefaultRateLimitPeriod = time.Minute
+)
+
+// RateLimitMode 限流模式
+type RateLimitMode uint8
+
+const (
+	// RateLimitWait 配额不足时阻塞等待，直至获得配额或 context 结束。
+	RateLimitWait RateLimitMode = iota
+	// RateLimitFailFast 配额不足时立即返回 *RateLimitError 。
+	RateLimitFailFast
+)
+
+// RateLimitError 客户端限流错误
+type RateLimitError struct {
+	// Key 被限流的机器人 webhook key
+	Key string
+	// RetryAfter 距离下一个可用配额的等待时长
+	RetryAfter time.Duration
+}
+
+// Error 返回文本形式的错误描述
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// RateLimiter 令牌桶限流器，可被多个 Bot 实例共享，并发安全。
+type RateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	interval time.Duration // 补充一个令牌所需的时长
+	last     time.Time
+}
+
+// NewRateLimiter 返回在 per 时长内最多允许 n 次请求的令牌桶限流器，桶初始为满。
+func NewRateLimiter(n int, per time.Duration) *RateLimiter {
+	if n <= 0 {
+		n = 1
+	}
+	return &RateLimiter{
+		capacity: float64(n),
+		tokens:   float64(n),
+		interval: per / time.Duration(n),
+		last:     time.Now(),
+	}
+}
+
+var sharedLimiters sync.Map // webhook key -> *RateLimiter
+
+// SharedRateLimiter 返回进程内 key 对应的共享限流器（每分钟20条）。相同 key 多次调用返回同一实例。
+func SharedRateLimiter(key string) *RateLimiter {
+	if l, ok := sharedLimiters.Load(key); ok {
+		return l.(*RateLimiter)
+	}
+	l, _ := sharedLimiters.LoadOrStore(key, NewRateLimiter(DefaultRateLimit, DefaultRateLimitPeriod))
+	return l.(*RateLimiter)
+}
+
+// reserve 尝试获取一个令牌。获取成功返回0，否则返回距离下一个可用令牌的等待时长。
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += float64(elapsed) / float64(l.interval)
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) * float64(l.interval))
+}
+
+// Allow 尝试立即获取一个配额，返回是否成功。
+func (l *RateLimiter) Allow() bool {
+	return l.reserve() == 0
+}
+
+// Wait 阻塞直至获得一个配额或 ctx 结束。
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// WithRateLimit 开启客户端限流。同一进程内以相同 key 创建的 Bot 共享每分钟20条的配额。
+func WithRateLimit(mode RateLimitMode) func(*Bot) {
+	return func(bot *Bot) {
+		bot.limiter = SharedRateLimiter(bot.key)
+		bot.limitMode = mode
+	}
+}
+
+// WithRateLimiter 使用指定的限流器开启客户端限流，可借此在多个 Bot 间共享配额。
+func WithRateLimiter(l *RateLimiter, mode RateLimitMode) func(*Bot) {
+	return func(bot *Bot) {
+		bot.limiter = l
+		bot.limitMode = mode
+	}
+}
+
+// acquire 按照限流模式获取一个发送配额
+func (bot *Bot) acquire(ctx context.Context) error {
+	if bot.limiter == nil {
+		return nil
+	}
+	if bot.limitMode == RateLimitWait {
+		return bot.limiter.Wait(ctx)
+	}
+	if wait := bot.limiter.reserve(); wait > 0 {
+		return &RateLimitError{Key: bot.key, RetryAfter: wait}
+	}
+	return nil
+}
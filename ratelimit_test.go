@@ -0,0 +1,115 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Refill(t *testing.T) {
+	l := NewRateLimiter(4, time.Second) // 每250ms补充一个令牌
+
+	// elapse 将上次补充的时间前移，模拟时间流逝。
+	elapse := func(d time.Duration) {
+		l.mu.Lock()
+		l.last = l.last.Add(-d)
+		l.mu.Unlock()
+	}
+	allowed := func() (n int) {
+		for l.Allow() {
+			n++
+		}
+		return n
+	}
+
+	if n := allowed(); n != 4 {
+		t.Errorf("full bucket allowed %d requests, want 4", n)
+	}
+	if wait := l.reserve(); wait <= 0 || wait > 250*time.Millisecond {
+		t.Errorf("reserve() on empty bucket = %s, want (0, 250ms]", wait)
+	}
+
+	elapse(500 * time.Millisecond)
+	if n := allowed(); n != 2 {
+		t.Errorf("after 500ms allowed %d requests, want 2", n)
+	}
+
+	// 补充的令牌不超过桶的容量
+	elapse(time.Hour)
+	if n := allowed(); n != 4 {
+		t.Errorf("after 1h allowed %d requests, want 4", n)
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	l := NewRateLimiter(1, 20*time.Millisecond)
+	if !l.Allow() {
+		t.Fatal("Allow() on full bucket = false")
+	}
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("Wait() returned after %s, want about 20ms", elapsed)
+	}
+
+	l = NewRateLimiter(1, time.Hour)
+	l.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	a := SharedRateLimiter("shared-key-a")
+	if SharedRateLimiter("shared-key-a") != a {
+		t.Error("SharedRateLimiter() returned different limiters for the same key")
+	}
+	if SharedRateLimiter("shared-key-b") == a {
+		t.Error("SharedRateLimiter() returned the same limiter for different keys")
+	}
+
+	bot1 := NewBot("shared-key-a", WithRateLimit(RateLimitWait))
+	bot2 := NewBot("shared-key-a", WithRateLimit(RateLimitFailFast))
+	if bot1.limiter != a || bot2.limiter != a {
+		t.Error("WithRateLimit() did not use the shared limiter of the key")
+	}
+}
+
+func TestBot_RateLimitFailFast(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	bot := NewBot("test-key", WithBaseURL(srv.URL), WithRateLimiter(NewRateLimiter(1, time.Hour), RateLimitFailFast))
+	if err := bot.SendText("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	var rle *RateLimitError
+	if err := bot.SendText("hello"); !errors.As(err, &rle) {
+		t.Fatalf("SendText() error = %v, want *RateLimitError", err)
+	}
+	if rle.Key != "test-key" || rle.RetryAfter <= 0 {
+		t.Errorf("RateLimitError = %+v, want key test-key and positive RetryAfter", rle)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
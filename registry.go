@@ -0,0 +1,174 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBotNotFound 指定名称的机器人不存在
+var ErrBotNotFound = errors.New("bot not found")
+
+// Registry 机器人注册表，按名称及标签管理多个机器人，可安全地并发使用。
+// 注册表中的机器人均以线程安全模式创建，并共享创建注册表时指定的选项（如 HTTP 客户端、重试策略、限流）。
+// 通过 Broadcast 发送的消息由多个机器人共享，须为不可变的。
+type Registry struct {
+	opts []func(*Bot)
+
+	mu    sync.RWMutex
+	bots  map[string]*registryEntry
+	names []string
+}
+
+type registryEntry struct {
+	bot  *Bot
+	tags map[string]bool
+}
+
+// NewRegistry 返回机器人注册表，opts 为所有机器人共享的选项。
+func NewRegistry(opts ...func(*Bot)) *Registry {
+	return &Registry{
+		opts: append([]func(*Bot){WithThreadSafe()}, opts...),
+		bots: make(map[string]*registryEntry),
+	}
+}
+
+// Add 创建并注册机器人，opts 在共享选项之后应用。名称已存在时替换原有的机器人。
+func (r *Registry) Add(name, key string, tags []string, opts ...func(*Bot)) *Bot {
+	all := make([]func(*Bot), 0, len(r.opts)+len(opts))
+	all = append(append(all, r.opts...), opts...)
+	bot := NewBot(key, all...)
+
+	entry := registryEntry{bot: bot, tags: make(map[string]bool, len(tags))}
+	for _, tag := range tags {
+		entry.tags[tag] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.bots[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.bots[name] = &entry
+	return bot
+}
+
+// Remove 移除机器人，返回机器人是否存在。
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.bots[name]; !ok {
+		return false
+	}
+	delete(r.bots, name)
+	for i, one := range r.names {
+		if one == name {
+			r.names = append(r.names[:i], r.names[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Get 返回指定名称的机器人
+func (r *Registry) Get(name string) (*Bot, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.bots[name]
+	if !ok {
+		return nil, false
+	}
+	return entry.bot, true
+}
+
+// ByTag 按注册顺序返回带有指定标签的机器人
+func (r *Registry) ByTag(tag string) []*Bot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var bots []*Bot
+	for _, name := range r.names {
+		if entry := r.bots[name]; entry.hasTags([]string{tag}) {
+			bots = append(bots, entry.bot)
+		}
+	}
+	return bots
+}
+
+// Names 按注册顺序返回同时带有全部指定标签的机器人名称，未指定标签时返回全部名称。
+func (r *Registry) Names(tags ...string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.names))
+	for _, name := range r.names {
+		if r.bots[name].hasTags(tags) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (entry *registryEntry) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !entry.tags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// BroadcastResult 广播消息时单个机器人的发送结果
+type BroadcastResult struct {
+	// Name 机器人名称
+	Name string
+	// Err 发送错误，成功时为 nil 。
+	Err error
+	// Elapsed 发送耗时
+	Elapsed time.Duration
+}
+
+// Broadcast 通过指定名称的机器人并行发送消息，未指定名称时发送给全部机器人。
+// 返回的结果与去重后的名称顺序一致；任一机器人发送失败时，返回的错误为各机器人错误的合并（ errors.Join ），
+// 每个错误均以机器人名称为前缀，可通过 errors.Is/errors.As 判断具体原因。名称不存在时对应的错误为 ErrBotNotFound 。
+// msg 会被多个机器人并发读取且不会被复制，须视为只读：广播期间调用方及中间件均不得修改消息（包括其中的切片等字段）。
+func (r *Registry) Broadcast(ctx context.Context, msg Message, names ...string) ([]*BroadcastResult, error) {
+	if len(names) == 0 {
+		names = r.Names()
+	}
+
+	seen := make(map[string]bool, len(names))
+	results := make([]*BroadcastResult, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			results = append(results, &BroadcastResult{Name: name})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, one := range results {
+		bot, ok := r.Get(one.Name)
+		if !ok {
+			one.Err = ErrBotNotFound
+			continue
+		}
+
+		wg.Add(1)
+		go func(bot *Bot, res *BroadcastResult) {
+			defer wg.Done()
+			start := time.Now()
+			res.Err = bot.Send(ctx, msg)
+			res.Elapsed = time.Since(start)
+		}(bot, one)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, one := range results {
+		if one.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", one.Name, one.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,162 @@
+package wecombot_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestRegistry_AddRemove(t *testing.T) {
+	reg := wecombot.NewRegistry()
+	reg.Add("a", "key-a", []string{"prod"})
+	reg.Add("b", "key-b", []string{"prod", "ops"})
+	reg.Add("c", "key-c", []string{"ops"})
+
+	if !reg.Remove("b") {
+		t.Error("Remove(b) = false, want true")
+	}
+	if reg.Remove("b") {
+		t.Error("Remove(b) twice = true, want false")
+	}
+	if _, ok := reg.Get("b"); ok {
+		t.Error("Get(b) found a removed bot")
+	}
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("Names() = %v, want [a c]", got)
+	}
+
+	// 重新添加已移除的名称时排在末尾
+	reg.Add("b", "key-b", nil)
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"a", "c", "b"}) {
+		t.Errorf("Names() = %v, want [a c b]", got)
+	}
+
+	// 替换已存在的名称时保留原有顺序，标签及机器人均被替换
+	old, _ := reg.Get("a")
+	bot := reg.Add("a", "key-a2", []string{"ops"})
+	if got, _ := reg.Get("a"); got != bot || got == old {
+		t.Errorf("Get(a) did not return the replacing bot")
+	}
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"a", "c", "b"}) {
+		t.Errorf("Names() = %v, want [a c b]", got)
+	}
+	if got := reg.Names("prod"); len(got) != 0 {
+		t.Errorf("Names(prod) = %v, want []", got)
+	}
+}
+
+func TestRegistry_Tags(t *testing.T) {
+	reg := wecombot.NewRegistry()
+	a := reg.Add("a", "key-a", []string{"prod", "backend"})
+	reg.Add("b", "key-b", []string{"prod"})
+	c := reg.Add("c", "key-c", []string{"backend", "prod", "ops"})
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: nil, want: []string{"a", "b", "c"}},
+		{tags: []string{"prod"}, want: []string{"a", "b", "c"}},
+		{tags: []string{"prod", "backend"}, want: []string{"a", "c"}},
+		{tags: []string{"backend", "ops"}, want: []string{"c"}},
+		{tags: []string{"prod", "missing"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := reg.Names(tt.tags...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Names(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+
+	if got := reg.ByTag("backend"); len(got) != 2 || got[0] != a || got[1] != c {
+		t.Errorf("ByTag(backend) returned %d bots, want [a c]", len(got))
+	}
+	if got := reg.ByTag("missing"); len(got) != 0 {
+		t.Errorf("ByTag(missing) returned %d bots, want 0", len(got))
+	}
+}
+
+func TestRegistry_BroadcastCanceled(t *testing.T) {
+	// block 模拟迟迟未响应的服务端，直至 ctx 被取消。
+	block := func(next wecombot.SendFunc) wecombot.SendFunc {
+		return func(ctx context.Context, req *wecombot.Request) (*wecombot.Response, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}
+	reg := wecombot.NewRegistry(wecombot.WithMiddleware(block))
+	reg.Add("a", "key-a", nil)
+	reg.Add("b", "key-b", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var msg wecombot.TextMessage
+	msg.MsgType = wecombot.TextMsgType
+	msg.Text.Content = "hello"
+	results, err := reg.Broadcast(ctx, &msg)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Broadcast() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Broadcast() returned %d results, want 2", len(results))
+	}
+	for _, one := range results {
+		if !errors.Is(one.Err, context.DeadlineExceeded) {
+			t.Errorf("result %s error = %v, want %v", one.Name, one.Err, context.DeadlineExceeded)
+		}
+	}
+}
+
+func TestRegistry_Broadcast(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	reg := wecombot.NewRegistry(wecombot.WithBaseURL(srv.URL))
+	reg.Add("ops-prod", "key-1", []string{"ops", "prod"})
+	reg.Add("ops-test", "key-2", []string{"ops", "test"})
+	reg.Add("dev-prod", "key-3", []string{"dev", "prod"})
+
+	if got := reg.Names("ops"); len(got) != 2 || got[0] != "ops-prod" || got[1] != "ops-test" {
+		t.Errorf("Names(ops) = %v", got)
+	}
+	if got := reg.ByTag("prod"); len(got) != 2 {
+		t.Errorf("ByTag(prod) returned %d bots, want 2", len(got))
+	}
+	if _, ok := reg.Get("dev-prod"); !ok {
+		t.Error("Get(dev-prod) ok = false")
+	}
+
+	msg := &wecombot.TextMessage{}
+	msg.Text.Content = "release v1.2.0"
+	results, err := reg.Broadcast(context.Background(), msg, append(reg.Names("prod"), "missing", "ops-prod")...)
+	if len(results) != 3 {
+		t.Fatalf("Broadcast() returned %d results, want 3", len(results))
+	}
+	if results[0].Err != nil || results[1].Err != nil || !errors.Is(results[2].Err, wecombot.ErrBotNotFound) {
+		t.Errorf("unexpected results: %v, %v, %v", results[0].Err, results[1].Err, results[2].Err)
+	}
+	if !errors.Is(err, wecombot.ErrBotNotFound) || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Broadcast() error = %v, want ErrBotNotFound for missing", err)
+	}
+
+	keys := make(map[string]bool)
+	for _, one := range srv.Messages() {
+		keys[one.Key] = true
+	}
+	if len(keys) != 2 || !keys["key-1"] || !keys["key-3"] {
+		t.Errorf("messages sent with keys %v, want key-1 and key-3", keys)
+	}
+
+	// 全部机器人
+	srv.Reset()
+	if _, err = reg.Broadcast(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpectMessageCount(t, 3)
+}
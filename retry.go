@@ -0,0 +1,96 @@
+package wecombot
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 重试策略。重试间隔按指数退避增长，并叠加随机抖动。
+type RetryPolicy struct {
+	// MaxAttempts 最大尝试次数（包含首次请求），小于等于1时不重试。
+	MaxAttempts int
+	// InitialInterval 首次重试前的等待时长
+	InitialInterval time.Duration
+	// MaxInterval 单次等待时长的上限，为0时不限制。
+	MaxInterval time.Duration
+	// Multiplier 每次重试后等待时长的增长倍数，小于1时按1处理。
+	Multiplier float64
+	// Jitter 随机抖动比例，取值范围[0, 1]。如0.2表示实际等待时长在计算值的±20%范围内随机。
+	Jitter float64
+	// MaxElapsedTime 自首次请求起允许重试的最长时间，为0时不限制。
+	MaxElapsedTime time.Duration
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	InitialInterval: 500 * time.Millisecond,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+	MaxElapsedTime:  30 * time.Second,
+}
+
+// WithRetry 设置重试策略。文件上传与消息发送各自独立重试，因此 SendFile/SendVoice 在消息发送失败时不会重复上传文件。
+func WithRetry(policy RetryPolicy) func(*Bot) {
+	return func(bot *Bot) {
+		bot.retry = &policy
+	}
+}
+
+// withRetry 按照重试策略执行 fn
+func (bot *Bot) withRetry(ctx context.Context, fn func() error) error {
+	if bot.retry == nil || bot.retry.MaxAttempts <= 1 {
+		return fn()
+	}
+	return bot.retry.do(ctx, fn)
+}
+
+func (p *RetryPolicy) do(ctx context.Context, fn func() error) (err error) {
+	start := time.Now()
+	interval := p.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= p.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		wait := p.jitter(interval)
+		if p.MaxElapsedTime > 0 && time.Since(start)+wait > p.MaxElapsedTime {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = p.next(interval)
+	}
+}
+
+// next 返回下一次重试的基准等待时长
+func (p *RetryPolicy) next(interval time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	interval = time.Duration(float64(interval) * multiplier)
+	if p.MaxInterval > 0 && interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+	return interval
+}
+
+// jitter 为等待时长叠加随机抖动
+func (p *RetryPolicy) jitter(interval time.Duration) time.Duration {
+	if p.Jitter <= 0 || interval <= 0 {
+		return interval
+	}
+	delta := p.Jitter * float64(interval)
+	return time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+}
@@ -0,0 +1,32 @@
+package wecombot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_do(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond, Multiplier: 2}
+
+	var attempts int
+	err := policy.do(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return NewResError(-1, "system busy")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("do() = %v after %d attempts, want nil after 3 attempts", err, attempts)
+	}
+
+	attempts = 0
+	err = policy.do(context.Background(), func() error {
+		attempts++
+		return NewResError(93000, "invalid webhook url")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("do() = %v after %d attempts, want error after 1 attempt", err, attempts)
+	}
+}
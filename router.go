@@ -0,0 +1,417 @@
+package wecombot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoRoute 消息未命中任何路由且未设置默认路由
+var ErrNoRoute = errors.New("no route matched")
+
+// Labels 消息标签，如 severity 、 service 、 env 。
+type Labels map[string]string
+
+// MatchOp 标签匹配运算符
+type MatchOp string
+
+const (
+	// MatchEqual 标签值等于指定值
+	MatchEqual MatchOp = "="
+	// MatchNotEqual 标签值不等于指定值
+	MatchNotEqual MatchOp = "!="
+	// MatchRegexp 标签值完整匹配正则表达式
+	MatchRegexp MatchOp = "=~"
+	// MatchNotRegexp 标签值不完整匹配正则表达式
+	MatchNotRegexp MatchOp = "!~"
+)
+
+// Matcher 标签匹配器。消息中不存在的标签按空字符串处理，因此 env!=prod 可匹配未设置 env 的消息。
+type Matcher struct {
+	// Label 标签名
+	Label string
+	// Op 匹配运算符
+	Op MatchOp
+	// Value 指定值或正则表达式
+	Value string
+
+	re *regexp.Regexp
+}
+
+// NewMatcher 返回标签匹配器，正则表达式须完整匹配标签值（自动添加 ^ 及 $ ）。
+func NewMatcher(label string, op MatchOp, value string) (*Matcher, error) {
+	m := Matcher{Label: label, Op: op, Value: value}
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ParseMatcher 解析形如 severity=critical 、 env!="test" 、 service=~"api|web" 的匹配器，值可使用双引号包裹。
+func ParseMatcher(s string) (*Matcher, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, "=!")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid matcher %q", s)
+	}
+
+	label, rest := strings.TrimSpace(s[:i]), s[i:]
+	var op MatchOp
+	for _, one := range []MatchOp{MatchRegexp, MatchNotEqual, MatchNotRegexp, MatchEqual} {
+		if strings.HasPrefix(rest, string(one)) {
+			op = one
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid matcher %q", s)
+	}
+
+	value := strings.TrimSpace(rest[len(op):])
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %v", s, err)
+		}
+		value = unquoted
+	}
+	return NewMatcher(label, op, value)
+}
+
+func (m *Matcher) compile() (err error) {
+	if m.Label == "" {
+		return errors.New("matcher label is required")
+	}
+	switch m.Op {
+	case MatchEqual, MatchNotEqual:
+		return nil
+	case MatchRegexp, MatchNotRegexp:
+		if m.re, err = regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+			return fmt.Errorf("invalid matcher %s: %v", m, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid matcher operator %q", m.Op)
+	}
+}
+
+// Matches 返回标签是否满足匹配器。正则匹配器须通过 NewMatcher 、 ParseMatcher 或 NewRouter 编译，否则始终返回 false 。
+func (m *Matcher) Matches(labels Labels) bool {
+	v := labels[m.Label]
+	switch m.Op {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp:
+		return m.re != nil && m.re.MatchString(v)
+	case MatchNotRegexp:
+		return m.re != nil && !m.re.MatchString(v)
+	}
+	return false
+}
+
+// String 返回文本形式的匹配器
+func (m *Matcher) String() string {
+	return m.Label + string(m.Op) + strconv.Quote(m.Value)
+}
+
+// MarshalJSON 将匹配器编码为 JSON 字符串
+func (m *Matcher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON 从 JSON 字符串解析匹配器，语法同 ParseMatcher 。
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	one, err := ParseMatcher(s)
+	if err != nil {
+		return err
+	}
+	*m = *one
+	return nil
+}
+
+// TimeWindow 路由生效的时间段
+type TimeWindow struct {
+	// Days 生效的星期，取值为 mon 、 tue 、 wed 、 thu 、 fri 、 sat 、 sun ，为空时每天生效。
+	Days []string `json:"days,omitempty"`
+	// Start 每天的开始时间（含），格式为 15:04 ，为空时为 00:00 。
+	Start string `json:"start,omitempty"`
+	// End 每天的结束时间（不含），格式为 15:04 ，为空时为 24:00 。早于 Start 时表示跨越午夜，如 22:00 至 08:00 。
+	End string `json:"end,omitempty"`
+	// Timezone 时区，如 Asia/Shanghai ，为空时使用本地时区。
+	Timezone string `json:"timezone,omitempty"`
+
+	days       map[time.Weekday]bool
+	start, end int // 自零点起的分钟数
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func (w *TimeWindow) compile() (err error) {
+	w.days = make(map[time.Weekday]bool, len(w.Days))
+	for _, day := range w.Days {
+		wd, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return fmt.Errorf("invalid day %q", day)
+		}
+		w.days[wd] = true
+	}
+
+	if w.start, err = parseClock(w.Start, 0); err != nil {
+		return err
+	}
+	if w.end, err = parseClock(w.End, 24*60); err != nil {
+		return err
+	}
+
+	w.loc = time.Local
+	if w.Timezone != "" {
+		if w.loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseClock 将 15:04 格式的时间解析为自零点起的分钟数
+func parseClock(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains 返回时间是否位于时间段内，星期按照时间段所在时区的日期判断。
+func (w *TimeWindow) contains(t time.Time) bool {
+	t = t.In(w.loc)
+	if len(w.days) > 0 && !w.days[t.Weekday()] {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if w.start <= w.end {
+		return m >= w.start && m < w.end
+	}
+	return m >= w.start || m < w.end
+}
+
+// Route 路由规则
+type Route struct {
+	// Name 路由名称
+	Name string `json:"name"`
+	// Match 标签匹配器，须全部满足，为空时匹配所有消息。
+	Match []*Matcher `json:"match,omitempty"`
+	// Time 生效的时间段，为 nil 时始终生效。
+	Time *TimeWindow `json:"time,omitempty"`
+	// Bots 命中后发送消息的机器人名称
+	Bots []string `json:"bots"`
+	// Continue 命中后是否继续匹配后续的路由
+	Continue bool `json:"continue,omitempty"`
+}
+
+// clone 返回路由规则的深拷贝，匹配器及时间段仅复制配置字段，须重新编译。
+func (r *Route) clone() *Route {
+	one := *r
+	one.Match = make([]*Matcher, 0, len(r.Match))
+	for _, m := range r.Match {
+		one.Match = append(one.Match, &Matcher{Label: m.Label, Op: m.Op, Value: m.Value})
+	}
+	if r.Time != nil {
+		one.Time = &TimeWindow{
+			Days:     append([]string(nil), r.Time.Days...),
+			Start:    r.Time.Start,
+			End:      r.Time.End,
+			Timezone: r.Time.Timezone,
+		}
+	}
+	one.Bots = append([]string(nil), r.Bots...)
+	return &one
+}
+
+// matches 返回消息标签及时间是否命中路由
+func (r *Route) matches(labels Labels, t time.Time) bool {
+	for _, m := range r.Match {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+	return r.Time == nil || r.Time.contains(t)
+}
+
+// RouterConfig 路由配置
+type RouterConfig struct {
+	// Routes 按顺序匹配的路由规则
+	Routes []*Route `json:"routes"`
+	// Default 未命中任何路由时发送消息的机器人名称
+	Default []string `json:"default,omitempty"`
+}
+
+// LoadRouterConfig 从 JSON 文件加载路由配置
+func LoadRouterConfig(filename string) (*RouterConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRouterConfig(data)
+}
+
+// ParseRouterConfig 解析 JSON 格式的路由配置，例如：
+//
+//	{
+//	  "routes": [
+//	    {"name": "critical", "match": ["severity=critical"], "bots": ["oncall"], "continue": true},
+//	    {"name": "api", "match": ["service=~\"api|gateway\"", "env!=test"], "bots": ["api-team"],
+//	     "time": {"days": ["mon", "tue", "wed", "thu", "fri"], "start": "09:00", "end": "18:00", "timezone": "Asia/Shanghai"}}
+//	  ],
+//	  "default": ["ops"]
+//	}
+func ParseRouterConfig(data []byte) (*RouterConfig, error) {
+	var cfg RouterConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Router 消息路由器。按顺序将消息标签与路由规则匹配，并通过注册表中的机器人发送消息。
+type Router struct {
+	registry *Registry
+	routes   []*Route
+	fallback []string
+}
+
+// NewRouter 返回消息路由器。路由规则不合法或引用了注册表中不存在的机器人时返回错误。
+// 路由器使用 cfg 的副本，之后修改 cfg 不会影响路由器，cfg 本身也不会被修改。未命名的路由以 #序号 （从0开始）命名。
+func NewRouter(reg *Registry, cfg *RouterConfig) (*Router, error) {
+	check := func(route string, bots []string) error {
+		for _, name := range bots {
+			if _, ok := reg.Get(name); !ok {
+				return fmt.Errorf("route %q: %w: %s", route, ErrBotNotFound, name)
+			}
+		}
+		return nil
+	}
+
+	routes := make([]*Route, 0, len(cfg.Routes))
+	for i, one := range cfg.Routes {
+		if one == nil {
+			return nil, fmt.Errorf("route #%d: is null", i)
+		}
+		for j, m := range one.Match {
+			if m == nil {
+				return nil, fmt.Errorf("route #%d: match[%d] is null", i, j)
+			}
+		}
+		route := one.clone()
+		if route.Name == "" {
+			route.Name = "#" + strconv.Itoa(i)
+		}
+		for _, m := range route.Match {
+			if err := m.compile(); err != nil {
+				return nil, fmt.Errorf("route %q: %w", route.Name, err)
+			}
+		}
+		if route.Time != nil {
+			if err := route.Time.compile(); err != nil {
+				return nil, fmt.Errorf("route %q: %w", route.Name, err)
+			}
+		}
+		if len(route.Bots) == 0 {
+			return nil, fmt.Errorf("route %q: bots is required", route.Name)
+		}
+		if err := check(route.Name, route.Bots); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	if err := check("default", cfg.Default); err != nil {
+		return nil, err
+	}
+
+	return &Router{
+		registry: reg,
+		routes:   routes,
+		fallback: append([]string(nil), cfg.Default...),
+	}, nil
+}
+
+// RoutePlan 消息的路由结果
+type RoutePlan struct {
+	// Routes 按顺序命中的路由名称，未命名的路由为 #序号 。
+	Routes []string
+	// Bots 去重后的机器人名称
+	Bots []string
+	// Default 是否因未命中任何路由而使用默认路由
+	Default bool
+}
+
+// DryRun 返回当前时间下消息标签将命中的路由及机器人，不会发送消息。
+func (rt *Router) DryRun(labels Labels) *RoutePlan {
+	return rt.DryRunAt(labels, time.Now())
+}
+
+// DryRunAt 返回指定时间下消息标签将命中的路由及机器人，不会发送消息。
+func (rt *Router) DryRunAt(labels Labels, t time.Time) *RoutePlan {
+	var plan RoutePlan
+	seen := make(map[string]bool)
+	add := func(bots []string) {
+		for _, name := range bots {
+			if !seen[name] {
+				seen[name] = true
+				plan.Bots = append(plan.Bots, name)
+			}
+		}
+	}
+
+	for _, route := range rt.routes {
+		if !route.matches(labels, t) {
+			continue
+		}
+		plan.Routes = append(plan.Routes, route.Name)
+		add(route.Bots)
+		if !route.Continue {
+			break
+		}
+	}
+	if len(plan.Routes) == 0 {
+		plan.Default = true
+		add(rt.fallback)
+	}
+	return &plan
+}
+
+// Send 按照路由规则发送消息，各机器人并行发送，返回值同 Registry.Broadcast 。
+// 未命中任何路由且未设置默认路由时返回 ErrNoRoute 。
+func (rt *Router) Send(ctx context.Context, msg Message, labels Labels) ([]*BroadcastResult, error) {
+	plan := rt.DryRun(labels)
+	if len(plan.Bots) == 0 {
+		return nil, ErrNoRoute
+	}
+	return rt.registry.Broadcast(ctx, msg, plan.Bots...)
+}
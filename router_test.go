@@ -0,0 +1,233 @@
+package wecombot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		in      string
+		labels  wecombot.Labels
+		want    bool
+		wantErr bool
+	}{
+		{in: "severity=critical", labels: wecombot.Labels{"severity": "critical"}, want: true},
+		{in: `severity = "critical"`, labels: wecombot.Labels{"severity": "warning"}, want: false},
+		{in: "env!=prod", labels: wecombot.Labels{}, want: true},
+		{in: `service=~"api|gateway"`, labels: wecombot.Labels{"service": "gateway"}, want: true},
+		{in: `service=~"api"`, labels: wecombot.Labels{"service": "api-v2"}, want: false},
+		{in: `service!~"api.*"`, labels: wecombot.Labels{"service": "web"}, want: true},
+		{in: `msg="a \"quoted\" value"`, labels: wecombot.Labels{"msg": `a "quoted" value`}, want: true},
+		{in: "severity", wantErr: true},
+		{in: "=critical", wantErr: true},
+		{in: "service=~(", wantErr: true},
+		{in: `env="prod`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m, err := wecombot.ParseMatcher(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMatcher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && m.Matches(tt.labels) != tt.want {
+				t.Errorf("%s.Matches(%v) = %v, want %v", m, tt.labels, !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+const testRouterConfig = `{
+	"routes": [
+		{"name": "critical", "match": ["severity=critical"], "bots": ["oncall"], "continue": true},
+		{"name": "api-daytime", "match": ["service=~\"api|gateway\"", "env!=test"], "bots": ["api-team"],
+		 "time": {"days": ["mon", "tue", "wed", "thu", "fri"], "start": "09:00", "end": "18:00", "timezone": "Asia/Shanghai"}},
+		{"name": "night", "match": ["env=prod"], "bots": ["oncall", "ops"], "time": {"start": "22:00", "end": "08:00", "timezone": "Asia/Shanghai"}},
+		{"name": "test", "match": ["env=test"], "bots": ["dev"]}
+	],
+	"default": ["ops"]
+}`
+
+func TestRouter_DryRun(t *testing.T) {
+	reg := wecombot.NewRegistry()
+	for _, name := range []string{"oncall", "api-team", "ops", "dev"} {
+		reg.Add(name, name+"-key", nil)
+	}
+	cfg, err := wecombot.ParseRouterConfig([]byte(testRouterConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err := wecombot.NewRouter(reg, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shanghai := time.FixedZone("CST", 8*60*60)
+	workday := time.Date(2024, 5, 15, 10, 0, 0, 0, shanghai) // 周三
+	weekend := time.Date(2024, 5, 18, 10, 0, 0, 0, shanghai) // 周六
+	night := time.Date(2024, 5, 18, 23, 30, 0, 0, shanghai)
+
+	tests := []struct {
+		name   string
+		labels wecombot.Labels
+		at     time.Time
+		want   *wecombot.RoutePlan
+	}{
+		{
+			name:   "critical api on workday",
+			labels: wecombot.Labels{"severity": "critical", "service": "api", "env": "prod"},
+			at:     workday,
+			want:   &wecombot.RoutePlan{Routes: []string{"critical", "api-daytime"}, Bots: []string{"oncall", "api-team"}},
+		},
+		{
+			name:   "api on weekend falls through to default",
+			labels: wecombot.Labels{"service": "api", "env": "staging"},
+			at:     weekend,
+			want:   &wecombot.RoutePlan{Bots: []string{"ops"}, Default: true},
+		},
+		{
+			name:   "critical at night deduplicates bots",
+			labels: wecombot.Labels{"severity": "critical", "env": "prod"},
+			at:     night,
+			want:   &wecombot.RoutePlan{Routes: []string{"critical", "night"}, Bots: []string{"oncall", "ops"}},
+		},
+		{
+			name:   "test env",
+			labels: wecombot.Labels{"service": "api", "env": "test"},
+			at:     workday,
+			want:   &wecombot.RoutePlan{Routes: []string{"test"}, Bots: []string{"dev"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rt.DryRunAt(tt.labels, tt.at); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DryRunAt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouter_Invalid(t *testing.T) {
+	reg := wecombot.NewRegistry()
+	reg.Add("ops", "ops-key", nil)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr error
+	}{
+		{name: "unknown bot", config: `{"routes": [{"name": "a", "bots": ["nobody"]}]}`, wantErr: wecombot.ErrBotNotFound},
+		{name: "unknown default bot", config: `{"default": ["nobody"]}`, wantErr: wecombot.ErrBotNotFound},
+		{name: "missing bots", config: `{"routes": [{"name": "a", "match": ["env=prod"]}]}`},
+		{name: "invalid day", config: `{"routes": [{"name": "a", "bots": ["ops"], "time": {"days": ["someday"]}}]}`},
+		{name: "invalid time", config: `{"routes": [{"name": "a", "bots": ["ops"], "time": {"start": "9am"}}]}`},
+		{name: "null route", config: `{"routes": [null]}`},
+		{name: "null route after valid route", config: `{"routes": [{"name": "a", "bots": ["ops"]}, null]}`},
+		{name: "null matcher", config: `{"routes": [{"name": "a", "match": [null], "bots": ["ops"]}]}`},
+		{name: "invalid timezone", config: `{"routes": [{"name": "a", "bots": ["ops"], "time": {"timezone": "Mars/Olympus"}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := wecombot.ParseRouterConfig([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = wecombot.NewRouter(reg, cfg)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("NewRouter() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := wecombot.ParseRouterConfig([]byte(`{"routes": [{"name": "a", "match": ["service=~("], "bots": ["ops"]}]}`)); err == nil {
+		t.Error("ParseRouterConfig() with invalid regexp error = nil")
+	}
+}
+
+func TestNewRouter_CopiesConfig(t *testing.T) {
+	reg := wecombot.NewRegistry()
+	for _, name := range []string{"ops", "dev"} {
+		reg.Add(name, name+"-key", nil)
+	}
+	cfg, err := wecombot.ParseRouterConfig([]byte(`{
+  "routes": [
+    {"match": ["env=~\"prod|staging\""], "bots": ["ops"], "time": {"days": ["mon"]}, "continue": true},
+    {"match": ["team=db"], "bots": ["dev"]}
+  ],
+  "default": ["ops"]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt, err := wecombot.NewRouter(reg, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(cfg); !bytes.Equal(got, orig) {
+		t.Errorf("NewRouter() modified config: %s, want %s", got, orig)
+	}
+
+	// 修改配置不影响已创建的路由器
+	cfg.Routes[0].Bots[0] = "dev"
+	cfg.Routes[0].Match[0].Value = "test"
+	cfg.Default[0] = "dev"
+
+	monday := time.Date(2024, 5, 13, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		labels wecombot.Labels
+		want   *wecombot.RoutePlan
+	}{
+		{labels: wecombot.Labels{"env": "prod", "team": "db"}, want: &wecombot.RoutePlan{Routes: []string{"#0", "#1"}, Bots: []string{"ops", "dev"}}},
+		{labels: wecombot.Labels{"env": "test"}, want: &wecombot.RoutePlan{Bots: []string{"ops"}, Default: true}},
+	}
+	for _, tt := range tests {
+		if got := rt.DryRunAt(tt.labels, monday); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DryRunAt(%v) = %+v, want %+v", tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRouter_Send(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	reg := wecombot.NewRegistry(wecombot.WithBaseURL(srv.URL))
+	reg.Add("oncall", "key-oncall", nil)
+	reg.Add("ops", "key-ops", nil)
+
+	cfg, err := wecombot.ParseRouterConfig([]byte(`{"routes": [{"name": "critical", "match": ["severity=critical"], "bots": ["oncall"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err := wecombot.NewRouter(reg, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &wecombot.TextMessage{}
+	msg.Text.Content = "db down"
+	if _, err = rt.Send(context.Background(), msg, wecombot.Labels{"severity": "critical"}); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := srv.Messages(); len(msgs) != 1 || msgs[0].Key != "key-oncall" {
+		t.Errorf("message not routed to oncall")
+	}
+
+	if _, err = rt.Send(context.Background(), msg, wecombot.Labels{"severity": "info"}); !errors.Is(err, wecombot.ErrNoRoute) {
+		t.Errorf("Send() error = %v, want %v", err, wecombot.ErrNoRoute)
+	}
+}
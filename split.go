@@ -0,0 +1,215 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MentionPlacement 拆分发送时提醒成员所附加的消息
+type MentionPlacement uint8
+
+const (
+	// MentionFirst 仅在第一条消息中提醒成员
+	MentionFirst MentionPlacement = iota
+	// MentionLast 仅在最后一条消息中提醒成员
+	MentionLast
+)
+
+// SplitOptions 长消息拆分选项
+type SplitOptions struct {
+	// Limit 每条消息的最大字节数，为0或超过消息类型的上限时使用该上限。
+	Limit int
+	// Numbered 是否在每条消息末尾追加形如 (1/3) 的序号，仅在拆分为多条消息时生效。
+	Numbered bool
+	// Mention 文本消息的提醒成员（mentioned_list 、 mentioned_mobile_list ）所附加的消息
+	Mention MentionPlacement
+}
+
+// markdownAtom 匹配拆分时不可被截断的 Markdown 片段：font 标签、链接、行内代码以及其他 HTML 标签（如 <@userid> ）。
+var markdownAtom = regexp.MustCompile("(?s)<font[^>]*>.*?</font>|\\[[^\\]\\n]*\\]\\([^)\\n]*\\)|`[^`\\n]*`|<[^>\\n]*>")
+
+// SplitText 将文本按不超过 limit 字节拆分为多段，优先在段落、行、空白处断开，且不会截断 UTF-8 字符。
+func SplitText(content string, limit int) []string {
+	return splitContent(content, limit, nil)
+}
+
+// SplitMarkdown 将 Markdown 内容按不超过 limit 字节拆分为多段，优先在段落、行（即 Markdown 块）、空白处断开，
+// 且不会截断 UTF-8 字符、 <font> 标签、链接及行内代码。
+func SplitMarkdown(content string, limit int) []string {
+	return splitContent(content, limit, markdownAtom.FindAllStringIndex(content, -1))
+}
+
+// splitSeparators 按优先级排列的断开位置
+var splitSeparators = []string{"\n\n", "\n", " "}
+
+func splitContent(content string, limit int, atoms [][]int) (parts []string) {
+	if limit <= 0 {
+		return []string{content}
+	}
+
+	// safe 返回在 i 处断开是否不会截断字符或不可拆分的片段
+	safe := func(i int) bool {
+		if i < len(content) && !utf8.RuneStart(content[i]) {
+			return false
+		}
+		for _, atom := range atoms {
+			if atom[0] < i && i < atom[1] {
+				return false
+			}
+		}
+		return true
+	}
+
+	start := 0
+	for len(content)-start > limit {
+		end := start + limit
+		cut := -1
+
+		// 优先选择不早于窗口一半位置的高优先级断开点，避免产生过短的片段。
+		for pass := 0; pass < 2 && cut < 0; pass++ {
+			for _, sep := range splitSeparators {
+				for i := strings.LastIndex(content[start:end], sep); i >= 0; i = strings.LastIndex(content[start:start+i], sep) {
+					at := start + i + len(sep)
+					if safe(at) && (pass == 1 || at-start >= limit/2) {
+						cut = at
+						break
+					}
+				}
+				if cut >= 0 {
+					break
+				}
+			}
+		}
+		if cut < 0 {
+			for cut = end; cut > start && !safe(cut); cut-- {
+			}
+		}
+		if cut <= start { // 不可拆分的片段超过了上限，只能在字符边界处截断。
+			for cut = end; cut > start && !utf8.RuneStart(content[cut]); cut-- {
+			}
+		}
+		if cut <= start { // 单个字符超过了上限
+			_, size := utf8.DecodeRuneInString(content[start:])
+			cut = start + size
+		}
+
+		if part := strings.TrimRight(content[start:cut], "\n"); part != "" {
+			parts = append(parts, part)
+		}
+		start = cut
+		for start < len(content) && content[start] == '\n' {
+			start++
+		}
+	}
+
+	if start < len(content) || len(parts) == 0 {
+		parts = append(parts, content[start:])
+	}
+	return parts
+}
+
+func partMarker(i, n int) string {
+	return fmt.Sprintf("\n(%d/%d)", i, n)
+}
+
+// ErrSplitLimit 拆分上限过小，无法容纳完整的字符及序号。
+var ErrSplitLimit = errors.New("split limit too small")
+
+// splitNumbered 拆分内容，并在需要时为每段预留及追加序号。
+// 内容需要拆分而上限（扣除序号后）不足 utf8.UTFMax 字节时返回 ErrSplitLimit ，无需拆分时不限制上限。
+func splitNumbered(content string, limit int, numbered bool, split func(string, int) []string) ([]string, error) {
+	parts := split(content, limit)
+	if len(parts) <= 1 {
+		return parts, nil
+	}
+	if limit < utf8.UTFMax {
+		return nil, fmt.Errorf("%w: %d bytes", ErrSplitLimit, limit)
+	}
+	if !numbered {
+		return parts, nil
+	}
+
+	for n := len(parts); ; n = len(parts) {
+		if room := limit - len(partMarker(n, n)); room < utf8.UTFMax {
+			return nil, fmt.Errorf("%w: %d bytes cannot hold part marker %q", ErrSplitLimit, limit, partMarker(n, n))
+		}
+		parts = split(content, limit-len(partMarker(n, n)))
+		if len(partMarker(len(parts), len(parts))) <= len(partMarker(n, n)) {
+			break
+		}
+	}
+	for i := range parts {
+		parts[i] += partMarker(i+1, len(parts))
+	}
+	return parts, nil
+}
+
+func (opts *SplitOptions) limit(max int) int {
+	if opts.Limit <= 0 || opts.Limit > max {
+		return max
+	}
+	return opts.Limit
+}
+
+// SendTextSplit 发送文本消息，内容超长时自动拆分为多条消息依次发送。
+func (bot *Bot) SendTextSplit(content string, split SplitOptions, opts ...func(*TextMessage)) error {
+	return bot.SendTextSplitContext(context.Background(), content, split, opts...)
+}
+
+// SendTextSplitContext 发送文本消息，内容超长时自动拆分为多条消息依次发送，可通过 ctx 取消或设置超时。
+// 内容为空时返回 *ValidationError 且不会发出请求。
+func (bot *Bot) SendTextSplitContext(ctx context.Context, content string, split SplitOptions, opts ...func(*TextMessage)) error {
+	if content == "" {
+		return new(TextMessage).Validate()
+	}
+	var tpl TextMessage
+	for _, setter := range opts {
+		setter(&tpl)
+	}
+
+	parts, err := splitNumbered(content, split.limit(MaxTextBytes), split.Numbered, SplitText)
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		var msg TextMessage
+		msg.Text.Content = part
+		if (split.Mention == MentionFirst && i == 0) || (split.Mention == MentionLast && i == len(parts)-1) {
+			msg.Text.MentionedList = tpl.Text.MentionedList
+			msg.Text.MentionedMobileList = tpl.Text.MentionedMobileList
+		}
+		if err := bot.SendTextMessageContext(ctx, &msg); err != nil {
+			return fmt.Errorf("send part %d/%d: %w", i+1, len(parts), err)
+		}
+	}
+	return nil
+}
+
+// SendMarkdownSplit 发送 Markdown 消息，内容超长时自动拆分为多条消息依次发送。
+func (bot *Bot) SendMarkdownSplit(content string, split SplitOptions) error {
+	return bot.SendMarkdownSplitContext(context.Background(), content, split)
+}
+
+// SendMarkdownSplitContext 发送 Markdown 消息，内容超长时自动拆分为多条消息依次发送，可通过 ctx 取消或设置超时。
+// 内容为空时返回 *ValidationError 且不会发出请求。
+func (bot *Bot) SendMarkdownSplitContext(ctx context.Context, content string, split SplitOptions) error {
+	if content == "" {
+		return new(MarkdownMessage).Validate()
+	}
+	parts, err := splitNumbered(content, split.limit(MaxMarkdownBytes), split.Numbered, SplitMarkdown)
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		var msg MarkdownMessage
+		msg.Markdown.Content = part
+		if err := bot.SendMarkdownMessageContext(ctx, &msg); err != nil {
+			return fmt.Errorf("send part %d/%d: %w", i+1, len(parts), err)
+		}
+	}
+	return nil
+}
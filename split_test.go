@@ -0,0 +1,119 @@
+package wecombot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int
+		want    []string
+	}{
+		{name: "无需拆分", content: "hello world", limit: 20, want: []string{"hello world"}},
+		{name: "优先按段落拆分", content: "aaaa bbbb\n\ncccc\ndddd", limit: 16, want: []string{"aaaa bbbb", "cccc\ndddd"}},
+		{name: "按行拆分", content: "aaaa\nbbbb\ncccc", limit: 10, want: []string{"aaaa\nbbbb", "cccc"}},
+		{name: "不截断UTF-8字符", content: "你好世界", limit: 7, want: []string{"你好", "世界"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.content, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SplitText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	content := `状态：<font color="warning">部分失败</font> 详情见 [构建日志](https://ci.example.com/build/1) 与 ` + "`make test`"
+	for limit := 50; limit < len(content); limit++ { // 不小于最长的不可拆分片段
+		for _, part := range SplitMarkdown(content, limit) {
+			if !utf8.ValidString(part) {
+				t.Fatalf("limit %d: part %q is not valid UTF-8", limit, part)
+			}
+			if strings.Count(part, "<font") != strings.Count(part, "</font>") ||
+				strings.Count(part, "[") != strings.Count(part, ")") ||
+				strings.Count(part, "`")%2 != 0 {
+				t.Fatalf("limit %d: part %q cuts inside a markdown element", limit, part)
+			}
+		}
+	}
+}
+
+func TestSplitNumbered(t *testing.T) {
+	parts, err := splitNumbered(strings.Repeat("line\n", 10), 16, true, SplitText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("splitNumbered() = %q, want multiple parts", parts)
+	}
+	for i, part := range parts {
+		if len(part) > 16 {
+			t.Errorf("part %q exceeds limit", part)
+		}
+		if !strings.HasSuffix(part, partMarker(i+1, len(parts))) {
+			t.Errorf("part %q has no marker", part)
+		}
+	}
+}
+
+func TestSplitNumbered_SmallLimit(t *testing.T) {
+	tests := []struct {
+		limit    int
+		numbered bool
+	}{
+		{limit: 3, numbered: false},
+		{limit: 7, numbered: true},  // 无法容纳 "\n(1/2)" 及一个完整字符
+		{limit: 10, numbered: true}, // 拆分为10段以上后序号变长，无法容纳 "\n(10/10)"
+	}
+	for _, tt := range tests {
+		_, err := splitNumbered(strings.Repeat("你好", 20), tt.limit, tt.numbered, SplitText)
+		if !errors.Is(err, ErrSplitLimit) {
+			t.Errorf("splitNumbered(limit=%d) error = %v, want %v", tt.limit, err, ErrSplitLimit)
+		}
+	}
+
+	// 无需拆分时不限制上限
+	for _, numbered := range []bool{false, true} {
+		if parts, err := splitNumbered("ok", 2, numbered, SplitText); err != nil || len(parts) != 1 || parts[0] != "ok" {
+			t.Errorf("splitNumbered(limit=2, numbered=%v) = %q, %v, want [ok], nil", numbered, parts, err)
+		}
+	}
+}
+
+func TestBot_SendSplitEmpty(t *testing.T) {
+	var calls int
+	count := func(next SendFunc) SendFunc {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			calls++
+			return next(ctx, req)
+		}
+	}
+	bot := NewBot("test-key", WithMiddleware(count))
+
+	var ve *ValidationError
+	if err := bot.SendTextSplit("", SplitOptions{}); !errors.As(err, &ve) {
+		t.Errorf("SendTextSplit() error = %v, want *ValidationError", err)
+	}
+	if err := bot.SendMarkdownSplit("", SplitOptions{Numbered: true}); !errors.As(err, &ve) {
+		t.Errorf("SendMarkdownSplit() error = %v, want *ValidationError", err)
+	}
+	if calls != 0 {
+		t.Errorf("sent %d requests, want 0", calls)
+	}
+}
+
+func TestSplitText_WideRune(t *testing.T) {
+	// 上限小于单个字符的字节数时，每段仅包含一个完整字符
+	parts := SplitText("你好", 2)
+	if len(parts) != 2 || parts[0] != "你" || parts[1] != "好" {
+		t.Errorf("SplitText() = %q, want [你 好]", parts)
+	}
+}
@@ -1,5 +1,7 @@
 package wecombot
 
+import "context"
+
 // TextNoticeTemplateCardMessage 文本通知模版卡片类型消息
 type TextNoticeTemplateCardMessage struct {
 	// MsgType 必填。消息类型，此时的消息类型固定为 template_card 。
@@ -100,7 +102,7 @@ type QuoteArea struct {
 
 // HorizontalContent 二级标题+文本列表
 type HorizontalContent struct {
-	// Type 模版卡片的二级标题信息内容支持的类型，1是url，2是文件附件，3代表点击跳转成员详情。
+	// Type 模版卡片的二级标题信息内容支持的类型，0（默认）是普通文本，1是url，2是文件附件，3代表点击跳转成员详情。
 	Type *uint8 `json:"type"`
 	// Keyname 二级标题，建议不超过5个字。
 	KeyName string `json:"keyname"`
@@ -186,14 +188,24 @@ const (
 
 // SendTextNoticeTemplateCardMessage 发送文本通知模板卡片类型消息
 func (bot *Bot) SendTextNoticeTemplateCardMessage(msg *TextNoticeTemplateCardMessage) error {
+	return bot.SendTextNoticeTemplateCardMessageContext(context.Background(), msg)
+}
+
+// SendTextNoticeTemplateCardMessageContext 发送文本通知模板卡片类型消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendTextNoticeTemplateCardMessageContext(ctx context.Context, msg *TextNoticeTemplateCardMessage) error {
 	msg.MsgType = TemplateCardMsgType
 	msg.TemplateCard.CardType = TextNoticeCardType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendNewsNoticeTemplateCardMessage 发送图文展示模板卡片类型消息
 func (bot *Bot) SendNewsNoticeTemplateCardMessage(msg *NewsNoticeTemplateCardMessage) error {
+	return bot.SendNewsNoticeTemplateCardMessageContext(context.Background(), msg)
+}
+
+// SendNewsNoticeTemplateCardMessageContext 发送图文展示模板卡片类型消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendNewsNoticeTemplateCardMessageContext(ctx context.Context, msg *NewsNoticeTemplateCardMessage) error {
 	msg.MsgType = TemplateCardMsgType
 	msg.TemplateCard.CardType = NewsNoticeCardType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
@@ -1,5 +1,7 @@
 package wecombot
 
+import "context"
+
 // TextMessage 文本类型消息。详见 https://developer.work.weixin.qq.com/document/path/91770#%E6%96%87%E6%9C%AC%E7%B1%BB%E5%9E%8B
 type TextMessage struct {
 	// MsgType 必填。消息类型，此时固定为：text。
@@ -30,17 +32,27 @@ func WithMentionedMobileList(mobile ...string) func(*TextMessage) {
 
 // SendTextMessage 发送文本消息
 func (bot *Bot) SendTextMessage(msg *TextMessage) error {
+	return bot.SendTextMessageContext(context.Background(), msg)
+}
+
+// SendTextMessageContext 发送文本消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendTextMessageContext(ctx context.Context, msg *TextMessage) error {
 	msg.MsgType = TextMsgType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendText 发送文本消息
 func (bot *Bot) SendText(content string, opts ...func(*TextMessage)) (err error) {
+	return bot.SendTextContext(context.Background(), content, opts...)
+}
+
+// SendTextContext 发送文本消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendTextContext(ctx context.Context, content string, opts ...func(*TextMessage)) (err error) {
 	var msg TextMessage
 	msg.Text.Content = content
 
 	for _, setter := range opts {
 		setter(&msg)
 	}
-	return bot.SendTextMessage(&msg)
+	return bot.SendTextMessageContext(ctx, &msg)
 }
@@ -2,9 +2,13 @@ package wecombot
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/textproto"
+	"net/url"
 )
 
 // FileType 文件类型
@@ -17,12 +21,92 @@ const (
 	VoiceFile FileType = "voice"
 )
 
+// ErrEmptyMediaID 文件上传未返回 media_id ，如中间件未调用 next 且未设置响应。
+var ErrEmptyMediaID = errors.New("upload returned empty media_id")
+
 func (bot *Bot) getUploadMediaURL(tpe FileType) string {
-	return fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/webhook/upload_media?key=%s&type=%s", bot.key, string(tpe))
+	return fmt.Sprintf("%s%s?key=%s&type=%s", bot.baseURL, uploadMediaPath, url.QueryEscape(bot.key), string(tpe))
 }
 
 // UploadMedia 文件上传。详见 https://developer.work.weixin.qq.com/document/path/91770#%E6%96%87%E4%BB%B6%E4%B8%8A%E4%BC%A0%E6%8E%A5%E5%8F%A3
 func (bot *Bot) UploadMedia(tpe FileType, f []byte, filename string) (*UploadedMedia, error) {
+	return bot.UploadMediaContext(context.Background(), tpe, f, filename)
+}
+
+// UploadMediaContext 文件上传，可通过 ctx 取消或设置超时。
+// 文件须满足上传限制，否则返回 *MediaSizeError 、 *VoiceFormatError 或 *VoiceDurationError 且不会发出请求。
+func (bot *Bot) UploadMediaContext(ctx context.Context, tpe FileType, f []byte, filename string) (*UploadedMedia, error) {
+	if err := checkMedia(tpe, f); err != nil {
+		return nil, err
+	}
+	return bot.upload(ctx, &MediaUpload{
+		Type:     tpe,
+		Filename: filename,
+		Data:     f,
+	})
+}
+
+// UploadMediaReader 以流的方式上传文件，size 为文件的字节数。文件内容不会被整体读入内存。
+func (bot *Bot) UploadMediaReader(tpe FileType, r io.Reader, size int64, filename string) (*UploadedMedia, error) {
+	return bot.UploadMediaReaderContext(context.Background(), tpe, r, size, filename)
+}
+
+// UploadMediaReaderContext 以流的方式上传文件，可通过 ctx 取消或设置超时。
+// 文件须满足上传限制，否则返回错误且不会发出请求（同 UploadMediaContext ）。仅当 r 实现了 io.Seeker 时才会按照重试策略重试。
+// 语音文件不超过 MaxVoiceBytes ，为校验格式及时长会被整体读入内存（最多读取 MaxVoiceBytes+1 字节）。
+func (bot *Bot) UploadMediaReaderContext(ctx context.Context, tpe FileType, r io.Reader, size int64, filename string) (*UploadedMedia, error) {
+	if err := checkMediaSize(tpe, size); err != nil {
+		return nil, err
+	}
+	if tpe == VoiceFile {
+		// 最多读取 MaxVoiceBytes+1 字节，实际内容超出限制时无需读完即可发现。
+		data, err := io.ReadAll(io.LimitReader(r, MaxVoiceBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if err = checkMediaSize(tpe, int64(len(data))); err != nil {
+			return nil, err
+		}
+		if int64(len(data)) != size {
+			return nil, fmt.Errorf("media reader returned %d bytes, want %d", len(data), size)
+		}
+		return bot.UploadMediaContext(ctx, tpe, data, filename)
+	}
+	return bot.upload(ctx, &MediaUpload{
+		Type:     tpe,
+		Filename: filename,
+		Reader:   r,
+		Size:     size,
+	})
+}
+
+func (bot *Bot) upload(ctx context.Context, upload *MediaUpload) (*UploadedMedia, error) {
+	res, err := bot.handler(ctx, &Request{Key: bot.key, Upload: upload})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || res.MediaID == "" {
+		return nil, ErrEmptyMediaID
+	}
+	return &UploadedMedia{
+		resData:   resData{ErrCode: res.ErrCode, ErrMsg: res.ErrMsg},
+		MediaID:   res.MediaID,
+		CreatedAt: res.CreatedAt,
+	}, nil
+}
+
+func mediaPartHeader(upload *MediaUpload, size int64) textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader, 2)
+	h.Set("Content-Disposition", mediaContentDisposition(upload.Filename, size))
+	h.Set("Content-Type", "application/octet-stream")
+	return h
+}
+
+func (bot *Bot) postMedia(ctx context.Context, upload *MediaUpload) (res *Response, err error) {
+	if upload.Reader != nil {
+		return bot.postMediaStream(ctx, upload)
+	}
+
 	var reqBody *bytes.Buffer
 	if bot.threadSafe {
 		reqBody = bytes.NewBuffer(nil)
@@ -33,27 +117,107 @@ func (bot *Bot) UploadMedia(tpe FileType, f []byte, filename string) (*UploadedM
 
 	writer := multipart.NewWriter(reqBody)
 
-	h := make(textproto.MIMEHeader, 2)
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="media"; filename="%s"; filelength=%d`, filename, len(f)))
-	h.Set("Content-Type", "application/octet-stream")
-
-	part, err := writer.CreatePart(h)
+	part, err := writer.CreatePart(mediaPartHeader(upload, int64(len(upload.Data))))
 	if err != nil {
 		return nil, err
 	}
-	if _, err = part.Write(f); err != nil {
+	if _, err = part.Write(upload.Data); err != nil {
 		return nil, err
 	}
 	writer.Close() // finishes the multipart message and writes the trailing boundary end line to the output.
 
-	var resData UploadedMedia
-	if err = bot.doPost(bot.getUploadMediaURL(tpe), map[string]string{"Content-Type": writer.FormDataContentType()}, reqBody, &resData); err != nil {
+	reqHeader := map[string]string{"Content-Type": writer.FormDataContentType()}
+	err = bot.withRetry(ctx, func() error {
+		res = new(Response)
+		if err := bot.doPost(ctx, bot.getUploadMediaURL(upload.Type), reqHeader, bytes.NewReader(reqBody.Bytes()), res); err != nil {
+			res = nil
+			return err
+		}
+		return res.toError()
+	})
+	return res, err
+}
+
+// postMediaStream 通过 io.Pipe 边读取边发送 multipart 请求体。
+func (bot *Bot) postMediaStream(ctx context.Context, upload *MediaUpload) (res *Response, err error) {
+	h := mediaPartHeader(upload, upload.Size)
+
+	// multipart 请求体除文件内容外的部分是确定的，预先计算出总长度，避免使用分块传输编码。
+	var head bytes.Buffer
+	mw := multipart.NewWriter(&head)
+	if _, err = mw.CreatePart(h); err != nil {
 		return nil, err
 	}
-	if err = resData.ToError(); err != nil {
+	length := int64(head.Len()) + upload.Size
+	head.Reset()
+	_ = mw.Close()
+	length += int64(head.Len())
+
+	reqHeader := map[string]string{"Content-Type": mw.FormDataContentType()}
+
+	attempt := func() error {
+		pr, pw := io.Pipe()
+		copied := make(chan error, 1)
+		go func() {
+			writer := multipart.NewWriter(pw)
+			_ = writer.SetBoundary(mw.Boundary())
+			err := func() error {
+				part, err := writer.CreatePart(h)
+				if err != nil {
+					return err
+				}
+				n, err := io.Copy(part, io.LimitReader(upload.Reader, upload.Size))
+				if err != nil {
+					return err
+				}
+				if n != upload.Size {
+					return fmt.Errorf("media reader returned %d bytes, want %d", n, upload.Size)
+				}
+				return writer.Close()
+			}()
+			pw.CloseWithError(err)
+			copied <- err
+		}()
+
+		res = new(Response)
+		err := bot.doPost(ctx, bot.getUploadMediaURL(upload.Type), reqHeader, &sizedReader{Reader: pr, size: length}, res)
+		pr.CloseWithError(io.ErrClosedPipe) // 请求提前结束时，使写入方的 goroutine 及时退出。
+		if cerr := <-copied; cerr != nil && cerr != io.ErrClosedPipe {
+			err = cerr // 读取文件内容失败时优先返回该错误
+		}
+		if err != nil {
+			res = nil
+			return err
+		}
+		return res.toError()
+	}
+
+	seeker, ok := upload.Reader.(io.Seeker)
+	if !ok {
+		// 无法回退读取位置，文件内容只能被发送一次，因此不重试。
+		return res, attempt()
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
 		return nil, err
 	}
-	return &resData, nil
+	first := true
+	err = bot.withRetry(ctx, func() error {
+		if !first {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		first = false
+		return attempt()
+	})
+	return res, err
+}
+
+// sizedReader 长度已知的请求体
+type sizedReader struct {
+	io.Reader
+	size int64
 }
 
 // UploadedMedia 上传媒体文件结果
@@ -0,0 +1,142 @@
+package wecombot_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestBot_SendFileReader(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "report.log")
+	content := bytes.Repeat([]byte("line\n"), 1000)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bot := srv.Bot("test-key")
+	if err := bot.SendFileFromPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if media := srv.ExpectMedia(t, "report.log"); media != nil && !bytes.Equal(media.Data, content) {
+		t.Errorf("uploaded %d bytes, want %d", len(media.Data), len(content))
+	}
+
+	// 文件大小超出限制时不会发出请求
+	var mse *wecombot.MediaSizeError
+	err := bot.SendVoiceReader(bytes.NewReader(nil), wecombot.MaxVoiceBytes+1, "big.amr")
+	if !errors.As(err, &mse) || !wecombot.IsPermanent(err) {
+		t.Errorf("SendVoiceReader() error = %v, want *MediaSizeError", err)
+	}
+
+	// 语音内容的实际大小超出限制时，最多读取 MaxVoiceBytes+1 字节即返回错误
+	big := bytes.NewReader(make([]byte, 2*wecombot.MaxVoiceBytes))
+	if err = bot.SendVoiceReader(big, 100, "big.amr"); !errors.As(err, &mse) {
+		t.Errorf("SendVoiceReader() with oversized reader error = %v, want *MediaSizeError", err)
+	}
+	if n := big.Len(); n != wecombot.MaxVoiceBytes-1 {
+		t.Errorf("%d bytes left unread, want %d", n, wecombot.MaxVoiceBytes-1)
+	}
+	if err = bot.SendVoiceReader(strings.NewReader("short"), 100, "short.amr"); err == nil {
+		t.Error("SendVoiceReader() with short reader error = nil")
+	}
+
+	// 读取的内容少于 size 时返回错误
+	if err = bot.SendFileReader(strings.NewReader("short"), 100, "short.txt"); err == nil {
+		t.Error("SendFileReader() with short reader error = nil")
+	}
+	if n := len(srv.Media()); n != 1 {
+		t.Errorf("received %d media, want 1", n)
+	}
+}
+
+func TestBot_UploadMediaReaderRetry(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	bot := srv.Bot("test-key", wecombot.WithRetry(wecombot.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+
+	// 可回退读取位置的 Reader 在重试时从头发送
+	srv.FailHTTP(http.StatusBadGateway)
+	ret, err := bot.UploadMediaReader(wecombot.NormalFile, strings.NewReader("hello world"), 11, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media := srv.ExpectMedia(t, "a.txt"); media != nil && (media.MediaID != ret.MediaID || string(media.Data) != "hello world") {
+		t.Errorf("uploaded media = %q (%s), want %q (%s)", media.Data, media.MediaID, "hello world", ret.MediaID)
+	}
+
+	// 无法回退读取位置的 Reader 不重试
+	srv.FailHTTP(http.StatusBadGateway)
+	r := io.MultiReader(strings.NewReader("hello world"))
+	var se *wecombot.HTTPStatusError
+	if _, err = bot.UploadMediaReader(wecombot.NormalFile, r, 11, "b.txt"); !errors.As(err, &se) {
+		t.Errorf("UploadMediaReader() error = %v, want *HTTPStatusError", err)
+	}
+}
+
+func TestBot_MediaCache(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	bot := srv.Bot("test-key", wecombot.WithMediaCache(wecombot.NewMemoryMediaCache()))
+	data := []byte("nightly report")
+	for i := 0; i < 2; i++ {
+		if err := bot.SendFile(data, "report.pdf"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(srv.Media()); n != 1 {
+		t.Errorf("uploaded %d times, want 1", n)
+	}
+
+	// media_id 失效时重新上传
+	srv.FailWith(40007, "invalid media_id")
+	if err := bot.SendFile(data, "report.pdf"); err != nil {
+		t.Fatal(err)
+	}
+	media, files := srv.Media(), srv.Files()
+	if len(media) != 2 || len(files) != 3 || files[2].File.MediaID != media[1].MediaID {
+		t.Errorf("got %d uploads and %d files, want re-upload after invalid media_id", len(media), len(files))
+	}
+
+	// 文件名不同时重新上传，以展示正确的文件名
+	if err := bot.SendFile(data, "report-copy.pdf"); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpectMedia(t, "report-copy.pdf")
+}
+
+func TestBot_SendVoice(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	frame := make([]byte, 32)
+	frame[0] = 7 << 3
+	voice := append([]byte("#!AMR\n"), bytes.Repeat(frame, 50)...)
+
+	bot := srv.Bot("test-key")
+	if err := bot.SendVoice(voice, `生日"祝福".amr`); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpectMedia(t, `生日"祝福".amr`)
+
+	var vfe *wecombot.VoiceFormatError
+	if err := bot.SendVoice([]byte("ID3\x03\x00\x00\x00"), "song.mp3"); !errors.As(err, &vfe) {
+		t.Errorf("SendVoice() error = %v, want *VoiceFormatError", err)
+	}
+	if n := len(srv.Media()); n != 1 {
+		t.Errorf("received %d media, want 1", n)
+	}
+}
@@ -0,0 +1,217 @@
+package wecombot
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 各类消息的长度及数量限制
+const (
+	// MaxTextBytes 文本消息内容的最大字节数
+	MaxTextBytes = 2048
+	// MaxMarkdownBytes Markdown 消息内容的最大字节数
+	MaxMarkdownBytes = 4096
+	// MaxImageBytes 图片（base64编码前）的最大字节数
+	MaxImageBytes = 2 << 20
+	// MaxArticles 图文消息的最大图文数量
+	MaxArticles = 8
+	// MaxHorizontalContents 模板卡片二级标题+文本列表的最大长度
+	MaxHorizontalContents = 6
+	// MaxJumps 模板卡片跳转指引样式列表的最大长度
+	MaxJumps = 3
+	// MaxVerticalContents 模板卡片二级垂直内容列表的最大长度
+	MaxVerticalContents = 4
+)
+
+// FieldError 字段校验错误
+type FieldError struct {
+	// Field 字段路径，如 template_card.jump_list[3] 。
+	Field string
+	// Rule 违反的规则
+	Rule string
+}
+
+// Error 返回文本形式的错误描述
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Rule
+}
+
+// ValidationError 消息校验错误，包含所有不合法的字段。
+type ValidationError struct {
+	// Errors 不合法的字段列表
+	Errors []*FieldError
+}
+
+// Error 返回文本形式的错误描述
+func (e *ValidationError) Error() string {
+	items := make([]string, 0, len(e.Errors))
+	for _, one := range e.Errors {
+		items = append(items, one.Error())
+	}
+	return "invalid message: " + strings.Join(items, "; ")
+}
+
+// WithValidation 开启发送前校验。开启后消息在发送前会先调用 Validate 方法，校验不通过则返回 *ValidationError 且不会发出请求。
+func WithValidation() func(*Bot) {
+	return func(bot *Bot) {
+		bot.validate = true
+	}
+}
+
+type validator struct {
+	errs []*FieldError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: field, Rule: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field string, ok bool) {
+	if !ok {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) content(field, content string, max int) {
+	if content == "" {
+		v.add(field, "is required")
+		return
+	}
+	if len(content) > max {
+		v.add(field, "must be at most %d bytes, got %d", max, len(content))
+	}
+	if !utf8.ValidString(content) {
+		v.add(field, "must be valid UTF-8")
+	}
+}
+
+func (v *validator) maxItems(field string, n, max int) {
+	if n > max {
+		v.add(field, "must contain at most %d items, got %d", max, n)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *TextMessage) Validate() error {
+	var v validator
+	v.content("text.content", msg.Text.Content, MaxTextBytes)
+	return v.err()
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *MarkdownMessage) Validate() error {
+	var v validator
+	v.content("markdown.content", msg.Markdown.Content, MaxMarkdownBytes)
+	return v.err()
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *MarkdownV2Message) Validate() error {
+	var v validator
+	v.content("markdown_v2.content", msg.MarkdownV2.Content, MaxMarkdownBytes)
+	return v.err()
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *ImageMessage) Validate() error {
+	var v validator
+	v.required("image.base64", msg.Image.Base64 != "")
+	v.required("image.md5", msg.Image.Md5 != "")
+	if msg.Image.Base64 != "" {
+		img, err := base64.StdEncoding.DecodeString(msg.Image.Base64)
+		switch {
+		case err != nil:
+			v.add("image.base64", "must be valid base64: %v", err)
+		case len(img) > MaxImageBytes:
+			v.add("image.base64", "decoded image must be at most %d bytes, got %d", MaxImageBytes, len(img))
+		case msg.Image.Md5 != "":
+			if sum := md5.Sum(img); !strings.EqualFold(hex.EncodeToString(sum[:]), msg.Image.Md5) {
+				v.add("image.md5", "does not match image content")
+			}
+		}
+	}
+	return v.err()
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *NewsMessage) Validate() error {
+	var v validator
+	v.required("news.articles", len(msg.News.Articles) > 0)
+	v.maxItems("news.articles", len(msg.News.Articles), MaxArticles)
+	for i, one := range msg.News.Articles {
+		field := fmt.Sprintf("news.articles[%d]", i)
+		if one == nil {
+			v.add(field, "must not be null")
+			continue
+		}
+		v.required(field+".title", one.Title != "")
+		v.required(field+".url", one.URL != "")
+	}
+	return v.err()
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *FileMessage) Validate() error {
+	var v validator
+	v.required("file.media_id", msg.File.MediaID != "")
+	return v.err()
+}
+
+// Validate 校验消息内容，返回的错误为 *ValidationError 。
+func (msg *VoiceMessage) Validate() error {
+	var v validator
+	v.required("voice.media_id", msg.Voice.MediaID != "")
+	return v.err()
+}
+
+// Validate 校验消息内容，包括列表长度以及各字段间的条件必填等约束，返回的错误为 *ValidationError 。
+func (msg *TextNoticeTemplateCardMessage) Validate() error {
+	card := &msg.TemplateCard
+
+	var v validator
+	v.maxItems("template_card.horizontal_content_list", len(card.HorizontalContentList), MaxHorizontalContents)
+	v.maxItems("template_card.jump_list", len(card.JumpList), MaxJumps)
+	checkCardRules(&cardView{
+		cardType:     TextNoticeCardType,
+		mainTitle:    &card.MainTitle,
+		subTitleText: card.SubTitleText,
+		quoteArea:    card.QuoteArea,
+		horizontals:  card.HorizontalContentList,
+		jumps:        card.JumpList,
+		cardAction:   &card.CardAction,
+	}, &v)
+	return v.err()
+}
+
+// Validate 校验消息内容，包括列表长度以及各字段间的条件必填等约束，返回的错误为 *ValidationError 。
+func (msg *NewsNoticeTemplateCardMessage) Validate() error {
+	card := &msg.TemplateCard
+
+	var v validator
+	v.maxItems("template_card.vertical_content_list", len(card.VerticalContentList), MaxVerticalContents)
+	v.maxItems("template_card.horizontal_content_list", len(card.HorizontalContentList), MaxHorizontalContents)
+	v.maxItems("template_card.jump_list", len(card.JumpList), MaxJumps)
+	checkCardRules(&cardView{
+		cardType:      NewsNoticeCardType,
+		mainTitle:     &card.MainTitle,
+		quoteArea:     card.QuoteArea,
+		imageTextArea: card.ImageTextArea,
+		cardImage:     &card.CardImage,
+		verticals:     card.VerticalContentList,
+		horizontals:   card.HorizontalContentList,
+		jumps:         card.JumpList,
+		cardAction:    &card.CardAction,
+	}, &v)
+	return v.err()
+}
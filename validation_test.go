@@ -0,0 +1,79 @@
+package wecombot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	longText := TextMessage{}
+	longText.Text.Content = strings.Repeat("a", MaxTextBytes+1)
+
+	var card TextNoticeTemplateCardMessage
+	for i := 0; i <= MaxJumps; i++ {
+		card.TemplateCard.JumpList = append(card.TemplateCard.JumpList, &Jump{Title: "企业微信官网"})
+	}
+	card.TemplateCard.CardAction.Type = uint8(ActionURL)
+
+	newsCard := NewNewsNoticeCard().
+		Image("https://wework.qpic.cn/wwpic/354393_4zpkKXd7SrGMvfg_1629280616/0", 3).
+		AddHorizontalMedia("附件", "成绩单.xlsx", "").
+		ActionMiniProgram("", "index.html").
+		Build()
+
+	// 空字符串与未设置同样视为缺失
+	empty := ""
+	emptyTitle := NewTextNoticeCard().ActionURL("https://work.weixin.qq.com").Build()
+	emptyTitle.TemplateCard.MainTitle.Title = &empty
+	emptyTitle.TemplateCard.SubTitleText = &empty
+
+	badType := uint8(9)
+	textCard := NewTextNoticeCard().Title("周报", "").ActionURL("https://work.weixin.qq.com").Build()
+	textCard.TemplateCard.HorizontalContentList = append(textCard.TemplateCard.HorizontalContentList, &HorizontalContent{Type: &badType, KeyName: "类型"})
+
+	var news NewsMessage
+	news.News.Articles = []*Article{{Title: "中秋节礼品领取"}}
+
+	tests := []struct {
+		name       string
+		msg        Message
+		wantFields []string
+	}{
+		{name: "合法的文本消息", msg: func() Message { var msg TextMessage; msg.Text.Content = "hello"; return &msg }()},
+		{name: "文本内容过长", msg: &longText, wantFields: []string{"text.content"}},
+		{name: "空的Markdown消息", msg: &MarkdownMessage{}, wantFields: []string{"markdown.content"}},
+		{name: "图文缺少链接", msg: &news, wantFields: []string{"news.articles[0].url"}},
+		{name: "文本通知模板卡片多处不合法", msg: &card, wantFields: []string{"template_card.jump_list", "template_card.main_title.title", "template_card.card_action.url"}},
+		{name: "文本通知模板卡片标题为空", msg: emptyTitle, wantFields: []string{"template_card.main_title.title"}},
+		{name: "二级标题类型不合法", msg: textCard, wantFields: []string{"template_card.horizontal_content_list[0].type"}},
+		{name: "图文展示模板卡片条件必填", msg: newsCard, wantFields: []string{"template_card.card_image.aspect_ratio", "template_card.card_action.appid", "template_card.horizontal_content_list[0].media_id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantFields) {
+				t.Fatalf("Validate() error = %v, want fields %v", err, tt.wantFields)
+			}
+			if strings.HasSuffix(tt.wantFields[0], "].type") && !strings.Contains(err.Error(), "0, 1, 2, 3") {
+				t.Errorf("Validate() error = %v, want the valid types 0, 1, 2, 3", err)
+			}
+			for i, field := range tt.wantFields {
+				if ve.Errors[i].Field != field {
+					t.Errorf("Validate().Errors[%d].Field = %v, want %v", i, ve.Errors[i].Field, field)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,12 @@
 package wecombot
 
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
 // VoiceMessage 语音类型消息。详见 https://developer.work.weixin.qq.com/document/path/91770#%E8%AF%AD%E9%9F%B3%E7%B1%BB%E5%9E%8B
 type VoiceMessage struct {
 	// MsgType 必填。语音类型，此时固定为 voice 。
@@ -13,18 +20,62 @@ type VoiceMessage struct {
 
 // SendVoiceMessage 发送语音消息
 func (bot *Bot) SendVoiceMessage(msg *VoiceMessage) (err error) {
+	return bot.SendVoiceMessageContext(context.Background(), msg)
+}
+
+// SendVoiceMessageContext 发送语音消息，可通过 ctx 取消或设置超时。
+func (bot *Bot) SendVoiceMessageContext(ctx context.Context, msg *VoiceMessage) (err error) {
 	msg.MsgType = VoiceMsgType
-	return bot.send(msg)
+	return bot.send(ctx, msg)
 }
 
 // SendVoice 发送语音
 func (bot *Bot) SendVoice(f []byte, filename string) (err error) {
-	ret, err := bot.UploadMedia(VoiceFile, f, filename)
+	return bot.SendVoiceContext(context.Background(), f, filename)
+}
+
+// SendVoiceContext 发送语音，可通过 ctx 取消或设置超时（包括文件上传与消息发送两个阶段）。若设置了 media_id 缓存，将复用有效期内相同内容的上传结果。
+func (bot *Bot) SendVoiceContext(ctx context.Context, f []byte, filename string) (err error) {
+	return bot.sendMedia(ctx, VoiceFile, f, filename, func(mediaID string) error {
+		var msg VoiceMessage
+		msg.Voice.MediaID = mediaID
+		return bot.SendVoiceMessageContext(ctx, &msg)
+	})
+}
+
+// SendVoiceReader 以流的方式上传并发送语音，size 为语音的字节数。
+func (bot *Bot) SendVoiceReader(r io.Reader, size int64, filename string) (err error) {
+	return bot.SendVoiceReaderContext(context.Background(), r, size, filename)
+}
+
+// SendVoiceReaderContext 以流的方式上传并发送语音，可通过 ctx 取消或设置超时（包括文件上传与消息发送两个阶段）。
+func (bot *Bot) SendVoiceReaderContext(ctx context.Context, r io.Reader, size int64, filename string) (err error) {
+	ret, err := bot.UploadMediaReaderContext(ctx, VoiceFile, r, size, filename)
 	if err != nil {
 		return err
 	}
 
 	var msg VoiceMessage
 	msg.Voice.MediaID = ret.MediaID
-	return bot.SendVoiceMessage(&msg)
+	return bot.SendVoiceMessageContext(ctx, &msg)
+}
+
+// SendVoiceFromPath 以流的方式上传并发送本地语音，文件名取自路径的最后一个元素。
+func (bot *Bot) SendVoiceFromPath(path string) (err error) {
+	return bot.SendVoiceFromPathContext(context.Background(), path)
+}
+
+// SendVoiceFromPathContext 以流的方式上传并发送本地语音，可通过 ctx 取消或设置超时（包括文件上传与消息发送两个阶段）。
+func (bot *Bot) SendVoiceFromPathContext(ctx context.Context, path string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return bot.SendVoiceReaderContext(ctx, f, fi.Size(), filepath.Base(path))
 }
@@ -0,0 +1,134 @@
+package wecombottest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/voidint/wecombot"
+)
+
+// Texts 返回已收到的文本消息
+func (s *Server) Texts() (items []*wecombot.TextMessage) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.TextMessage); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// Markdowns 返回已收到的 Markdown 消息
+func (s *Server) Markdowns() (items []*wecombot.MarkdownMessage) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.MarkdownMessage); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// MarkdownV2s 返回已收到的 Markdown V2 消息
+func (s *Server) MarkdownV2s() (items []*wecombot.MarkdownV2Message) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.MarkdownV2Message); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// Images 返回已收到的图片消息
+func (s *Server) Images() (items []*wecombot.ImageMessage) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.ImageMessage); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// News 返回已收到的图文消息
+func (s *Server) News() (items []*wecombot.NewsMessage) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.NewsMessage); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// Files 返回已收到的文件消息
+func (s *Server) Files() (items []*wecombot.FileMessage) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.FileMessage); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// Voices 返回已收到的语音消息
+func (s *Server) Voices() (items []*wecombot.VoiceMessage) {
+	for _, msg := range s.Messages() {
+		if one, ok := msg.Body.(*wecombot.VoiceMessage); ok {
+			items = append(items, one)
+		}
+	}
+	return items
+}
+
+// ExpectMessageCount 断言已收到的消息数量
+func (s *Server) ExpectMessageCount(t testing.TB, n int) {
+	t.Helper()
+	if got := len(s.Messages()); got != n {
+		t.Errorf("wecombottest: got %d messages, want %d", got, n)
+	}
+}
+
+// ExpectText 断言恰好收到一条内容包含 substr 的文本消息，并返回该消息。
+func (s *Server) ExpectText(t testing.TB, substr string) *wecombot.TextMessage {
+	t.Helper()
+	var matched []*wecombot.TextMessage
+	for _, msg := range s.Texts() {
+		if strings.Contains(msg.Text.Content, substr) {
+			matched = append(matched, msg)
+		}
+	}
+	if len(matched) != 1 {
+		t.Errorf("wecombottest: got %d text messages containing %q, want 1", len(matched), substr)
+		return nil
+	}
+	return matched[0]
+}
+
+// ExpectMarkdown 断言恰好收到一条内容包含 substr 的 Markdown 消息，并返回该消息。
+func (s *Server) ExpectMarkdown(t testing.TB, substr string) *wecombot.MarkdownMessage {
+	t.Helper()
+	var matched []*wecombot.MarkdownMessage
+	for _, msg := range s.Markdowns() {
+		if strings.Contains(msg.Markdown.Content, substr) {
+			matched = append(matched, msg)
+		}
+	}
+	if len(matched) != 1 {
+		t.Errorf("wecombottest: got %d markdown messages containing %q, want 1", len(matched), substr)
+		return nil
+	}
+	return matched[0]
+}
+
+// ExpectMedia 断言恰好收到一个名为 filename 的上传文件，并返回该文件。
+func (s *Server) ExpectMedia(t testing.TB, filename string) *Media {
+	t.Helper()
+	var matched []*Media
+	for _, one := range s.Media() {
+		if one.Filename == filename {
+			matched = append(matched, one)
+		}
+	}
+	if len(matched) != 1 {
+		t.Errorf("wecombottest: got %d uploaded files named %q, want 1", len(matched), filename)
+		return nil
+	}
+	return matched[0]
+}
@@ -0,0 +1,276 @@
+// Package wecombottest 提供进程内的企业微信群机器人 webhook 模拟服务，便于在单元测试中验证消息发送逻辑。
+package wecombottest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/voidint/wecombot"
+)
+
+// Message 模拟服务收到的消息
+type Message struct {
+	// Key 机器人 webhook key
+	Key string
+	// Type 消息类型
+	Type wecombot.MsgType
+	// Body 解码后的消息，如 *wecombot.TextMessage 、 *wecombot.MarkdownMessage 等。
+	Body wecombot.Message
+	// Raw 原始请求体
+	Raw []byte
+}
+
+// Media 模拟服务收到的上传文件
+type Media struct {
+	// Key 机器人 webhook key
+	Key string
+	// Type 文件类型
+	Type wecombot.FileType
+	// Filename 文件名
+	Filename string
+	// Data 文件内容
+	Data []byte
+	// MediaID 模拟服务分配的 media_id
+	MediaID string
+}
+
+// Fault 注入的故障，每个故障仅作用于一次请求。
+type Fault struct {
+	// StatusCode 非0时以该 HTTP 状态码响应
+	StatusCode int
+	// ErrCode 非0时以该企业微信错误码响应
+	ErrCode int
+	// ErrMsg 企业微信错误信息
+	ErrMsg string
+	// Latency 响应前的延迟
+	Latency time.Duration
+}
+
+// Server 企业微信群机器人 webhook 模拟服务，实现了 /cgi-bin/webhook/send 和 /cgi-bin/webhook/upload_media 接口。
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []*Message
+	media    []*Media
+	faults   []Fault
+	latency  time.Duration
+	seq      int
+}
+
+// NewServer 启动并返回模拟服务，使用完毕后需调用 Close 方法。
+func NewServer() *Server {
+	s := new(Server)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cgi-bin/webhook/send", s.handleSend)
+	mux.HandleFunc("/cgi-bin/webhook/upload_media", s.handleUploadMedia)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Bot 返回指向模拟服务的机器人实例
+func (s *Server) Bot(key string, opts ...func(*wecombot.Bot)) *wecombot.Bot {
+	return wecombot.NewBot(key, append([]func(*wecombot.Bot){wecombot.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// WebhookURL 返回指向模拟服务的 webhook 地址
+func (s *Server) WebhookURL(key string) string {
+	return fmt.Sprintf("%s/cgi-bin/webhook/send?key=%s", s.URL, key)
+}
+
+// InjectFault 注入故障。故障按注入顺序依次作用于后续的请求。
+func (s *Server) InjectFault(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, faults...)
+}
+
+// FailWith 令下一次请求以指定的企业微信错误码响应
+func (s *Server) FailWith(errCode int, errMsg string) {
+	s.InjectFault(Fault{ErrCode: errCode, ErrMsg: errMsg})
+}
+
+// FailHTTP 令下一次请求以指定的 HTTP 状态码响应
+func (s *Server) FailHTTP(statusCode int) {
+	s.InjectFault(Fault{StatusCode: statusCode})
+}
+
+// SetLatency 设置每次请求的响应延迟
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Messages 返回已收到的全部消息
+func (s *Server) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.messages...)
+}
+
+// Media 返回已收到的全部上传文件
+func (s *Server) Media() []*Media {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Media(nil), s.media...)
+}
+
+// Reset 清空已收到的消息、文件以及尚未生效的故障
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+	s.media = nil
+	s.faults = nil
+	s.latency = 0
+}
+
+type resData struct {
+	ErrCode   int    `json:"errcode"`
+	ErrMsg    string `json:"errmsg"`
+	MediaID   string `json:"media_id,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	Type      string `json:"type,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, res *resData) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// intercept 按照延迟及注入的故障处理请求，返回 true 表示请求已被处理。
+func (s *Server) intercept(w http.ResponseWriter, r *http.Request) bool {
+	// 读取完整的请求体，以便在客户端断开连接时及时感知。
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return true
+	}
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+
+	s.mu.Lock()
+	latency := s.latency
+	var fault *Fault
+	if len(s.faults) > 0 {
+		fault = &s.faults[0]
+		s.faults = s.faults[1:]
+	}
+	s.mu.Unlock()
+
+	if fault != nil {
+		latency += fault.Latency
+	}
+	if latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return true
+		case <-time.After(latency):
+		}
+	}
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return true
+	}
+	if fault != nil && fault.StatusCode != 0 {
+		w.WriteHeader(fault.StatusCode)
+		return true
+	}
+	if fault != nil && fault.ErrCode != 0 {
+		writeJSON(w, &resData{ErrCode: fault.ErrCode, ErrMsg: fault.ErrMsg})
+		return true
+	}
+	if r.URL.Query().Get("key") == "" {
+		writeJSON(w, &resData{ErrCode: 93000, ErrMsg: "invalid webhook url"})
+		return true
+	}
+	return false
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	if s.intercept(w, r) {
+		return
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, err := wecombot.ParseMessage(raw)
+	if err != nil {
+		writeJSON(w, &resData{ErrCode: 40008, ErrMsg: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.messages = append(s.messages, &Message{
+		Key:  r.URL.Query().Get("key"),
+		Type: body.Type(),
+		Body: body,
+		Raw:  raw,
+	})
+	s.mu.Unlock()
+
+	writeJSON(w, &resData{ErrMsg: "ok"})
+}
+
+func (s *Server) handleUploadMedia(w http.ResponseWriter, r *http.Request) {
+	if s.intercept(w, r) {
+		return
+	}
+
+	tpe := wecombot.FileType(r.URL.Query().Get("type"))
+	if tpe != wecombot.NormalFile && tpe != wecombot.VoiceFile {
+		writeJSON(w, &resData{ErrCode: 40004, ErrMsg: "invalid media type"})
+		return
+	}
+
+	part, err := func() (*Media, error) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			p, err := reader.NextPart()
+			if err != nil {
+				return nil, err
+			}
+			if p.FormName() != "media" {
+				continue
+			}
+			data, err := io.ReadAll(p)
+			if err != nil {
+				return nil, err
+			}
+			return &Media{Filename: p.FileName(), Data: data}, nil
+		}
+	}()
+	if err != nil {
+		writeJSON(w, &resData{ErrCode: 41001, ErrMsg: "missing media: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.seq++
+	part.Key = r.URL.Query().Get("key")
+	part.Type = tpe
+	part.MediaID = fmt.Sprintf("fake-media-%d", s.seq)
+	s.media = append(s.media, part)
+	s.mu.Unlock()
+
+	writeJSON(w, &resData{
+		ErrMsg:    "ok",
+		Type:      string(tpe),
+		MediaID:   part.MediaID,
+		CreatedAt: strconv.FormatInt(time.Now().Unix(), 10),
+	})
+}
@@ -0,0 +1,79 @@
+package wecombottest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/voidint/wecombot"
+	"github.com/voidint/wecombot/wecombottest"
+)
+
+func TestServer_Send(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	bot := srv.Bot("test-key")
+	if err := bot.SendText("hello", wecombot.WithMentionedList("@all")); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.SendMarkdown("# 成绩单"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.ExpectMessageCount(t, 2)
+	if msg := srv.ExpectText(t, "hello"); msg != nil && len(msg.Text.MentionedList) != 1 {
+		t.Errorf("mentioned_list = %v, want [@all]", msg.Text.MentionedList)
+	}
+	srv.ExpectMarkdown(t, "成绩单")
+}
+
+func TestServer_SendFile(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	if err := srv.Bot("test-key").SendFile([]byte("hello world"), "hello.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	media := srv.ExpectMedia(t, "hello.txt")
+	files := srv.Files()
+	if media == nil || len(files) != 1 || files[0].File.MediaID != media.MediaID {
+		t.Errorf("file message does not refer to uploaded media")
+	}
+}
+
+func TestServer_Fault(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	srv.FailWith(93000, "invalid webhook url")
+	err := srv.Bot("test-key").SendText("hello")
+	var re *wecombot.ResError
+	if !errors.As(err, &re) || re.ErrCode() != 93000 {
+		t.Errorf("SendText() error = %v, want ResError 93000", err)
+	}
+
+	srv.FailHTTP(http.StatusBadGateway)
+	srv.FailWith(-1, "system busy")
+	bot := srv.Bot("test-key", wecombot.WithRetry(wecombot.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+	if err = bot.SendText("hello"); err != nil {
+		t.Errorf("SendText() with retry error = %v", err)
+	}
+	srv.ExpectText(t, "hello")
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := wecombottest.NewServer()
+	defer srv.Close()
+
+	srv.SetLatency(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := srv.Bot("test-key").SendTextContext(ctx, "hello"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendTextContext() error = %v, want context.DeadlineExceeded", err)
+	}
+}